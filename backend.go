@@ -0,0 +1,338 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/executor/gogit"
+	"msgtm/pkg/output"
+	"msgtm/pkg/usecase"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	shellBackend = "shell"
+	goGitBackend = "go-git"
+)
+
+// executors are the usecase executors of the selected git backend.
+// They are built after the flags are parsed, so commands must read them at run time.
+type executors struct {
+	getter          usecase.CommitTagGetter
+	register        usecase.RegisterServiceTags
+	list            usecase.ListTags
+	localDestroyer  usecase.DestroyServiceTags
+	remoteDestroyer usecase.DestroyServiceTags
+	pusher          usecase.CommitPusher
+	finder          usecase.CommitFinder
+	refs            usecase.ListTagRefs
+	remoteRefs      usecase.ListRemoteTagRefs
+	fetcher         usecase.FetchTags
+	ancestor        usecase.IsAncestor
+	branches        usecase.ListBranches
+	commits         usecase.ListCommits
+	files           usecase.WriteFile
+	committer       usecase.CreateCommit
+	commitResetter  usecase.ResetCommit
+	// hooksDir finds the directory git hooks are installed to, only hooks install needs it.
+	hooksDir func() (string, error)
+	// root is the top level directory of the repository selected by -C/--repo,
+	// relative file names are resolved from it. It is empty for the working directory.
+	root string
+	// configFile is the path of the config file.
+	configFile string
+	// plan records the mutating operations instead of executing them in a dry run, nil otherwise.
+	plan *usecase.Plan
+	// stateDiff is the change a dry run would make to the state file.
+	stateDiff string
+	// output is the format of the results of the commands.
+	output output.Format
+	// exitCode is the exit code of msgtm, 1 after a command failed.
+	exitCode int
+}
+
+func (e *executors) init(backend string, repo string, dryRun bool, logger *slog.Logger) error {
+	var err error
+	switch backend {
+	case shellBackend:
+		*e, err = shellExecutors(repo, logger)
+	case goGitBackend:
+		*e, err = goGitExecutors(repo)
+	default:
+		return fmt.Errorf("unknown backend: %s, backend should be %s or %s", backend, shellBackend, goGitBackend)
+	}
+	if err != nil {
+		return err
+	}
+	e.files = &executor.FileWriter{}
+	if dryRun {
+		e.dryRun()
+	}
+	e.decorateLogging(logger)
+	// a failure in the middle of tagging several services must not leave some of them tagged
+	e.register = &usecase.TransactionalRegister{
+		Register:  e.register,
+		Destroyer: e.localDestroyer,
+		List:      e.refs,
+	}
+	return nil
+}
+
+func shellExecutors(repo string, logger *slog.Logger) (executors, error) {
+	gitExecutor := executor.LogDecorateToExecutor(
+		executor.GitShellCommandExecutorIn(repo),
+		logger,
+		func(output string) string {
+			split := strings.Split(output, "\n")
+			return split[0] + " ... " + "output line length: " + fmt.Sprintf("%d", len(split))
+		},
+	)
+	root := ""
+	if repo != "" {
+		var err error
+		root, err = executor.RepositoryRoot(gitExecutor)
+		if err != nil {
+			return executors{}, fmt.Errorf("failed to find repository %s: %w", repo, err)
+		}
+	}
+	origin := domain.Origin
+	return executors{
+		root: root,
+		hooksDir: func() (string, error) {
+			return executor.HooksDir(gitExecutor)
+		},
+		getter: &executor.CommitTagGetter{
+			GitCommandExecutor: gitExecutor,
+		},
+		register: executor.NewGitTagRegister(gitExecutor),
+		list: &executor.GitTagList{
+			GitCommandExecutor: gitExecutor,
+		},
+		localDestroyer: &executor.LocalServiceTagsDestroyer{
+			GitCommandExecutor: gitExecutor,
+		},
+		remoteDestroyer: &executor.RemoteServiceTagsDestroyer{
+			Remote:             &origin,
+			GitCommandExecutor: gitExecutor,
+		},
+		pusher: &executor.GitTagPusher{
+			GitCommandExecutor: gitExecutor,
+		},
+		finder: &executor.CommitFinder{
+			GitCommandExecutor: gitExecutor,
+		},
+		refs: &executor.GitTagRefList{
+			GitCommandExecutor: gitExecutor,
+		},
+		remoteRefs: &executor.GitRemoteTagRefList{
+			GitCommandExecutor: gitExecutor,
+		},
+		fetcher: &executor.GitTagFetcher{
+			GitCommandExecutor: gitExecutor,
+		},
+		ancestor: &executor.GitAncestorChecker{
+			GitCommandExecutor: gitExecutor,
+		},
+		branches: &executor.GitBranchList{
+			GitCommandExecutor: gitExecutor,
+		},
+		commits: &executor.GitCommitList{
+			GitCommandExecutor: gitExecutor,
+		},
+		committer: &executor.GitCommitter{
+			GitCommandExecutor: gitExecutor,
+		},
+		commitResetter: &executor.GitCommitResetter{
+			GitCommandExecutor: gitExecutor,
+		},
+	}, nil
+}
+
+func goGitExecutors(dir string) (executors, error) {
+	root := ""
+	if dir == "" {
+		dir = "."
+	}
+	repo, err := gogit.OpenRepository(dir)
+	if err != nil {
+		return executors{}, fmt.Errorf("failed to open repository: %w", err)
+	}
+	if dir != "." {
+		root, err = gogit.RepositoryRoot(repo)
+		if err != nil {
+			return executors{}, fmt.Errorf("failed to find repository %s: %w", dir, err)
+		}
+	}
+	auth := gogit.AuthFromEnv()
+	origin := domain.Origin
+	return executors{
+		root: root,
+		hooksDir: func() (string, error) {
+			worktree, err := gogit.RepositoryRoot(repo)
+			if err != nil {
+				return "", err
+			}
+			// core.hooksPath is not supported without the git binary
+			return filepath.Join(worktree, ".git", "hooks"), nil
+		},
+		getter: &gogit.CommitTagGetter{
+			Repository: repo,
+		},
+		register: gogit.NewTagRegister(repo),
+		list: &gogit.TagList{
+			Repository: repo,
+		},
+		localDestroyer: &gogit.LocalServiceTagsDestroyer{
+			Repository: repo,
+		},
+		remoteDestroyer: &gogit.RemoteServiceTagsDestroyer{
+			Remote:     &origin,
+			Auth:       auth,
+			Repository: repo,
+		},
+		pusher: &gogit.TagPusher{
+			Auth:       auth,
+			Repository: repo,
+		},
+		finder: &gogit.CommitFinder{
+			Repository: repo,
+		},
+		refs: &gogit.TagRefList{
+			Repository: repo,
+		},
+		remoteRefs: &gogit.RemoteTagRefList{
+			Auth:       auth,
+			Repository: repo,
+		},
+		fetcher: &gogit.TagFetcher{
+			Auth:       auth,
+			Repository: repo,
+		},
+		ancestor: &gogit.AncestorChecker{
+			Repository: repo,
+		},
+		branches: &gogit.BranchList{
+			Repository: repo,
+		},
+		commits: &gogit.CommitList{
+			Repository: repo,
+		},
+		committer: &gogit.Committer{
+			Repository: repo,
+		},
+		commitResetter: &gogit.CommitResetter{
+			Repository: repo,
+		},
+	}, nil
+}
+
+// path resolves a relative file name from the root of the selected repository.
+func (e *executors) path(fileName string) string {
+	if e.root == "" || filepath.IsAbs(fileName) {
+		return fileName
+	}
+	return filepath.Join(e.root, fileName)
+}
+
+// dryRun replaces the command executors with the ones of a plan,
+// the query executors still read the repository.
+func (e *executors) dryRun() {
+	origin := domain.Origin
+	e.plan = usecase.NewPlan(e.finder)
+	e.register = e.plan.Register()
+	e.localDestroyer = e.plan.LocalDestroyer()
+	e.remoteDestroyer = e.plan.RemoteDestroyer(&origin)
+	e.pusher = e.plan.Pusher()
+	e.fetcher = e.plan.Fetcher()
+	e.refs = e.plan.Refs(e.refs)
+	e.files = e.plan.FileWriter()
+	e.committer = e.plan.Committer()
+	e.commitResetter = e.plan.CommitResetter()
+}
+
+// printPlan prints the operations and the state file changes of a dry run.
+func (e *executors) printPlan() {
+	// the changes of JSON and YAML results are marked as a dry run instead
+	if e.plan == nil || e.output.Structured() {
+		return
+	}
+	fmt.Println("Dry run, planned operations:")
+	if len(e.plan.Operations) == 0 {
+		fmt.Println("  nothing to do")
+	}
+	for _, operation := range e.plan.Operations {
+		fmt.Printf("  %s\n", operation)
+	}
+	if e.stateDiff != "" {
+		fmt.Print(e.stateDiff)
+	}
+}
+
+func (e *executors) decorateLogging(logger *slog.Logger) {
+	e.getter = &executor.LoggingQueryExecutor[usecase.GetCommitTagQuery, *[]domain.GitTag]{
+		Executor: e.getter,
+		Logger:   logger,
+	}
+	e.register = &executor.LoggingCommandExecutor[usecase.RegisterServiceTagsCommand]{
+		Executor: e.register,
+		Logger:   logger,
+	}
+	e.list = &executor.LoggingQueryExecutor[usecase.ListTagsQuery, *[]domain.GitTag]{
+		Executor: e.list,
+		Logger:   logger,
+	}
+	e.localDestroyer = &executor.LoggingCommandExecutor[usecase.DestroyServiceTagsCommand]{
+		Executor: e.localDestroyer,
+		Logger:   logger,
+	}
+	e.remoteDestroyer = &executor.LoggingCommandExecutor[usecase.DestroyServiceTagsCommand]{
+		Executor: e.remoteDestroyer,
+		Logger:   logger,
+	}
+	e.pusher = &executor.LoggingCommandExecutor[usecase.CommitPushCommand]{
+		Executor: e.pusher,
+		Logger:   logger,
+	}
+	e.finder = &executor.LoggingQueryExecutor[usecase.FindCommitQuery, *domain.CommitId]{
+		Executor: e.finder,
+		Logger:   logger,
+	}
+	e.refs = &executor.LoggingQueryExecutor[usecase.ListTagRefsQuery, *[]domain.TagRef]{
+		Executor: e.refs,
+		Logger:   logger,
+	}
+	e.remoteRefs = &executor.LoggingQueryExecutor[usecase.ListRemoteTagRefsQuery, *[]domain.TagRef]{
+		Executor: e.remoteRefs,
+		Logger:   logger,
+	}
+	e.fetcher = &executor.LoggingCommandExecutor[usecase.FetchTagsCommand]{
+		Executor: e.fetcher,
+		Logger:   logger,
+	}
+	e.ancestor = &executor.LoggingQueryExecutor[usecase.IsAncestorQuery, bool]{
+		Executor: e.ancestor,
+		Logger:   logger,
+	}
+	e.branches = &executor.LoggingQueryExecutor[usecase.ListBranchesQuery, *[]domain.Branch]{
+		Executor: e.branches,
+		Logger:   logger,
+	}
+	e.commits = &executor.LoggingQueryExecutor[usecase.ListCommitsQuery, *[]domain.Commit]{
+		Executor: e.commits,
+		Logger:   logger,
+	}
+	e.files = &executor.LoggingCommandExecutor[usecase.WriteFileCommand]{
+		Executor: e.files,
+		Logger:   logger,
+	}
+	e.committer = &executor.LoggingCommandExecutor[usecase.CreateCommitCommand]{
+		Executor: e.committer,
+		Logger:   logger,
+	}
+	e.commitResetter = &executor.LoggingCommandExecutor[usecase.ResetCommitCommand]{
+		Executor: e.commitResetter,
+		Logger:   logger,
+	}
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"log/slog"
+	"msgtm/pkg/cioutput"
+	"msgtm/pkg/config"
 	"msgtm/pkg/domain"
 	"msgtm/pkg/executor"
+	"msgtm/pkg/notify"
+	"msgtm/pkg/output"
+	"msgtm/pkg/provider"
+	"msgtm/pkg/registry"
 	"msgtm/pkg/subcmd"
 	"msgtm/pkg/usecase"
+	"net/http"
 	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,84 +30,107 @@ type CommitId string
 const HEAD CommitId = "HEAD"
 
 func main() {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	logs := &logWriter{Writer: os.Stdout}
+	logger := slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 
-	gitExecutor := executor.LogDecorateToExecutor(
-		executor.GitShellCommandExecutor(),
-		logger,
-		func(output string) string {
-			split := strings.Split(output, "\n")
-			return split[0] + " ... " + "output line length: " + fmt.Sprintf("%d", len(split))
-		},
-	)
-
-	getter := &executor.LoggingQueryExecutor[usecase.GetCommitTagQuery, *[]domain.GitTag]{
-		Executor: &executor.CommitTagGetter{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
-	register := &executor.LoggingCommandExecutor[usecase.RegisterServiceTagsCommand]{
-		Executor: executor.NewGitTagRegister(gitExecutor),
-		Logger:   logger,
-	}
-	list := &executor.LoggingQueryExecutor[usecase.ListTagsQuery, *[]domain.GitTag]{
-		Executor: &executor.GitTagList{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
-	localDestroyer := &executor.LoggingCommandExecutor[usecase.DestroyServiceTagsCommand]{
-		Executor: &executor.LocalServiceTagsDestroyer{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
-	remoteDestroyer := &executor.LoggingCommandExecutor[usecase.DestroyServiceTagsCommand]{
-		Executor: &executor.RemoteServiceTagsDestroyer{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
-	pusher := &executor.LoggingCommandExecutor[usecase.CommitPushCommand]{
-		Executor: &executor.GitTagPusher{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
-	finder := &executor.LoggingQueryExecutor[usecase.FindCommitQuery, *domain.CommitId]{
-		Executor: &executor.CommitFinder{
-			GitCommandExecutor: gitExecutor,
-		},
-		Logger: logger,
-	}
+	e := &executors{}
 
 	rootCmd := &cobra.Command{
 		Use:   "msgtn",
 		Short: "msgtn is a tool for multi service git tag manager",
 	}
+	rootCmd.PersistentFlags().String("config", config.DefaultFileName, "Config file")
+	rootCmd.PersistentFlags().String("backend", shellBackend, "Git backend, shell runs the git binary and go-git reads the repository directly")
+	rootCmd.PersistentFlags().StringP("repo", "C", "", "Repository to operate on, relative file names are resolved from its root (the repo of the config file by default)")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Print the tag, push and state file operations instead of executing them")
+	rootCmd.PersistentFlags().String("output", string(output.Text), "Output of list, status, add, upgrade, push and reset (text, table, json or yaml)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		backend, _ := cmd.Flags().GetString("backend")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		repo, _ := cmd.Flags().GetString("repo")
+		configFile, _ := cmd.Flags().GetString("config")
+		name, _ := cmd.Flags().GetString("output")
+		format, err := output.ParseFormat(name)
+		if err != nil {
+			// like an invalid flag, before any command runs
+			fmt.Fprintf(os.Stderr, "Error: invalid argument %q for \"--output\" flag: %s\n", name, err.Error())
+			os.Exit(2)
+		}
+		if _, ok := cmd.Annotations[resultOnStdout]; ok || format.Structured() {
+			// scripts read the result from stdout
+			logs.Writer = os.Stderr
+		}
+		if _, ok := cmd.Annotations[withoutRepository]; ok {
+			// -C only sets the directory relative file names are resolved from
+			e.root = repo
+			e.output = format
+			e.configFile = e.path(configFile)
+			return nil
+		}
+		if repo == "" {
+			// an invalid config file is reported by the commands reading it
+			if cfg, err := config.Load(configFile); err == nil {
+				repo = cfg.RepoPath(configFile)
+			}
+		}
+		if err := e.init(backend, repo, dryRun, logger); err != nil {
+			return err
+		}
+		e.output = format
+		e.configFile = configFile
+		if cmd.Flags().Changed("repo") {
+			e.configFile = e.path(configFile)
+		}
+		return nil
+	}
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		e.printPlan()
+	}
 
-	rootCmd.AddCommand(listCmd(logger, list, finder))
-	rootCmd.AddCommand(tagAddCmd(logger, register, list, finder))
-	rootCmd.AddCommand(tagVersionUpCmd(logger, list, register, getter, finder))
-	rootCmd.AddCommand(tagResetCmd(logger, getter, localDestroyer, remoteDestroyer, list, finder))
-	rootCmd.AddCommand(tagsPushCmd(logger, getter, pusher))
-	rootCmd.AddCommand(syncAllCmd(list, finder))
-	rootCmd.AddCommand(initCmd(logger))
+	rootCmd.AddCommand(listCmd(logger, e))
+	rootCmd.AddCommand(tagAddCmd(logger, e))
+	rootCmd.AddCommand(tagVersionUpCmd(logger, e))
+	rootCmd.AddCommand(tagResetCmd(logger, e))
+	rootCmd.AddCommand(tagsPushCmd(logger, e))
+	rootCmd.AddCommand(syncAllCmd(e))
+	rootCmd.AddCommand(pullCmd(logger, e))
+	rootCmd.AddCommand(publishCmd(logger, e))
+	rootCmd.AddCommand(imagesCmd(logger, e))
+	rootCmd.AddCommand(ciOutputCmd(logger, e))
+	rootCmd.AddCommand(envCmd(logger, e))
+	rootCmd.AddCommand(manifestsCmd(logger, e))
+	rootCmd.AddCommand(statusCmd(logger, e))
+	rootCmd.AddCommand(workspaceCmd(logger, e))
+	rootCmd.AddCommand(hooksCmd(logger, e))
+	rootCmd.AddCommand(initCmd(logger, e))
+	rootCmd.AddCommand(schemaCmd(logger, e))
+	rootCmd.AddCommand(validateCmd(logger, e))
 
 	if err := rootCmd.Execute(); err != nil {
-		panic(err)
+		// cobra has printed the error, e.g. of an unknown flag or a stray argument
+		os.Exit(1)
 	}
+	// the commands record their failure, so that the state file is synced and the plan printed before exiting
+	os.Exit(e.exitCode)
 }
 
 type CobraCmdRunner func(cmd *cobra.Command, args []string)
 
-func initCmd(logger *slog.Logger) *cobra.Command {
+// withoutRepository annotates the commands that do not read or change a repository,
+// so that they run outside of one without setting up the git backend.
+const withoutRepository = "without-repository"
+
+// resultOnStdout annotates the commands whose standard output is read by scripts, e.g. with eval,
+// their logs and errors are written to stderr whatever the output format.
+const resultOnStdout = "result-on-stdout"
+
+func initCmd(logger *slog.Logger, e *executors) *cobra.Command {
 	f := func() CobraCmdRunner {
 		return func(cmd *cobra.Command, args []string) {
 			fileName, _ := cmd.Flags().GetString("filename")
+			fileName = e.path(fileName)
 			services, _ := cmd.Flags().GetStringSlice("services")
 			serviceConfigs := make([]domain.ServiceName, 0)
 			for _, service := range services {
@@ -107,28 +140,29 @@ func initCmd(logger *slog.Logger) *cobra.Command {
 			stateWriter := domain.InitStateWriter(serviceConfigs...)
 			file, err := os.Create(fileName)
 			if err != nil {
-				fmt.Printf("Failed to create file: %s\n", err.Error())
+				e.fail("Failed to create file", err)
 				return
 			}
 			err = stateWriter.Write(file, domain.YAML)
 			if err != nil {
-				fmt.Printf("Failed to write file: %s\n", err.Error())
+				e.fail("Failed to write file", err)
 				return
 			}
 		}
 	}
 	initCmd := &cobra.Command{
-		Use:   "init",
-		Short: "init is a tool for multi service git tag manager",
-		Run:   f(),
+		Use:         "init",
+		Annotations: map[string]string{withoutRepository: ""},
+		Short:       "init is a tool for multi service git tag manager",
+		Run:         f(),
 	}
 	initCmd.Flags().StringP("filename", "f", "services-state.yaml", "filename")
 	initCmd.Flags().StringSliceP("services", "s", []string{}, "services")
 	return initCmd
 }
 
-func syncAll(writer io.Writer, state *domain.WritedState, list usecase.ListTags, finder usecase.CommitFinder) error {
-	state, err := usecase.SyncAllServiceTagState(state, list, finder)
+func syncAll(writer io.Writer, state *domain.WritedState, list usecase.ListTagRefs) error {
+	state, err := usecase.SyncAllServiceTagState(state, list)
 	if err != nil {
 		return err
 	}
@@ -141,35 +175,62 @@ func syncAll(writer io.Writer, state *domain.WritedState, list usecase.ListTags,
 
 func addSyncAll(
 	f CobraCmdRunner,
-	list usecase.ListTags,
-	finder usecase.CommitFinder,
+	e *executors,
 ) CobraCmdRunner {
 	return func(cmd *cobra.Command, args []string) {
 		f(cmd, args)
 		sync, _ := cmd.Flags().GetBool("sync")
 		fileName, _ := cmd.Flags().GetString("state-file")
+		fileName = e.path(fileName)
 		if sync {
-			file, err := os.OpenFile(fileName, os.O_RDWR|os.O_TRUNC, os.ModePerm)
+			state, err := subcmd.ReadStateFile(fileName)
 			if err != nil {
-				fmt.Printf("Failed to open file: %s\n", err.Error())
+				fmt.Fprintf(sideOutput(e), "Failed to read file: %s\n", err.Error())
+				return
+			}
+			if e.plan != nil {
+				err = planSyncAll(fileName, state, e)
+				if err != nil {
+					fmt.Fprintf(sideOutput(e), "Failed to sync all service tags: %s\n", err.Error())
+				}
 				return
 			}
-			state, err := domain.FromReader(file, domain.YAML)
+			// the state file is only replaced once the tags are listed, a failure leaves it as it was
+			after := &bytes.Buffer{}
+			err = syncAll(after, state, e.refs)
 			if err != nil {
-				fmt.Printf("Failed to read file: %s\n", err.Error())
+				fmt.Fprintf(sideOutput(e), "Failed to sync all service tags: %s\n", err.Error())
 				return
 			}
-			err = syncAll(file, state, list, finder)
+			err = os.WriteFile(fileName, after.Bytes(), 0o644)
 			if err != nil {
-				fmt.Printf("Failed to sync all service tags: %s\n", err.Error())
+				fmt.Fprintf(sideOutput(e), "Failed to write file: %s\n", err.Error())
 				return
 			}
 		}
 	}
 }
 
-func syncAllCmd(list usecase.ListTags, finder usecase.CommitFinder) *cobra.Command {
-	f := addSyncAll(func(_ *cobra.Command, _ []string) {}, list, finder)
+// planSyncAll records the write of the state file and its changes instead of writing it.
+func planSyncAll(fileName string, state *domain.WritedState, e *executors) error {
+	before, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	after := &bytes.Buffer{}
+	err = syncAll(after, state, e.refs)
+	if err != nil {
+		return err
+	}
+	e.stateDiff = subcmd.UnifiedDiff(fileName, string(before), after.String())
+	if e.stateDiff != "" {
+		e.plan.Record("write %s", fileName)
+	}
+	return nil
+}
+
+func syncAllCmd(e *executors) *cobra.Command {
+	f := addSyncAll(func(_ *cobra.Command, _ []string) {}, e)
 	syncAllCmd := &cobra.Command{
 		Use:   "sync",
 		Short: "sync is a tool for multi service git tag manager",
@@ -180,190 +241,1109 @@ func syncAllCmd(list usecase.ListTags, finder usecase.CommitFinder) *cobra.Comma
 	return syncAllCmd
 }
 
-func listCmd(logger *slog.Logger, list usecase.ListTags, finder usecase.CommitFinder) *cobra.Command {
-	f := func(list usecase.ListTags, finder usecase.CommitFinder) CobraCmdRunner {
+func listCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(e *executors) CobraCmdRunner {
 		return func(cmd *cobra.Command, args []string) {
 			services, _ := cmd.Flags().GetStringSlice("services")
 			isAll, _ := cmd.Flags().GetBool("isAll")
+			remote, _ := cmd.Flags().GetString("remote")
+			result := output.NewTagList()
 			err := subcmd.LogSubCommandDecorator(
-				subcmd.ServiceTagsListCommand(list, finder),
+				subcmd.ServiceTagsListCommand(e.refs, e.remoteRefs, result),
 				logger,
 			)(subcmd.ServiceTagsListParameter{
 				Filter: services,
 				IsAll:  isAll,
+				Remote: remote,
 			})
-			if err != nil {
-				fmt.Printf("Failed to list service tags: %s\n", err.Error())
-				return
-			}
+			printResult(e, result, err, "Failed to list service tags")
 		}
 	}
 	serviceTagsListCmd := &cobra.Command{
 		Use:   "list",
 		Short: "list is a tool for multi service git tag manager",
-		Run:   f(list, finder),
+		Run:   f(e),
 	}
 	serviceTagsListCmd.Flags().StringSliceP("services", "s", []string{}, "services")
 	serviceTagsListCmd.Flags().Bool("isAll", true, "List all service tags")
+	serviceTagsListCmd.Flags().StringP("remote", "r", "", "List the service tags of the remote instead of the local ones")
 	return serviceTagsListCmd
 }
 
-func tagAddCmd(logger *slog.Logger, register usecase.RegisterServiceTags, list usecase.ListTags, finder usecase.CommitFinder) *cobra.Command {
-	f := func(register usecase.RegisterServiceTags) CobraCmdRunner {
+func tagAddCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(e *executors) CobraCmdRunner {
 		return func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				fmt.Println("Error: tag add command must version args.")
-				return
-			}
+			result := newChangeList(e)
 			version := args[0]
 			commitIdStr, _ := cmd.Flags().GetString("commit-id")
 			services, _ := cmd.Flags().GetStringSlice("services")
 			fileName, _ := cmd.Flags().GetString("from-config-file")
+			allowDowngrade, _ := cmd.Flags().GetBool("allow-downgrade")
+			allowSkip, _ := cmd.Flags().GetBool("allow-skip")
+			if fileName != "" {
+				fileName = e.path(fileName)
+			}
 
 			param := subcmd.TagAddCommandParameter{
 				Version:        version,
 				CommitId:       commitIdStr,
 				Services:       services,
 				FromConfigFile: fileName,
+				VersionPolicy: usecase.VersionPolicy{
+					AllowDowngrade: allowDowngrade,
+					AllowSkip:      allowSkip,
+				},
+				PushParameter:    pushParameter(cmd),
+				PublishParameter: publishParameter(cmd),
 			}
 
-			err := subcmd.LogSubCommandDecorator(
-				subcmd.TagAddCommand(register),
-				logger,
-			)(param)
-
+			register, err := branchPolicyRegister(e, e.register)
 			if err != nil {
-				fmt.Printf("Failed to add service tags: %s\n", err.Error())
+				printResult(e, result, err, "Failed to load config")
+				return
 			}
-
+			notifier, err := releaseNotifier(cmd, e, logger)
+			if err != nil {
+				printResult(e, result, err, "Failed to configure notifications")
+				return
+			}
+			err = subcmd.LogSubCommandDecorator(
+				subcmd.TagAddCommand(register, e.pusher, e.localDestroyer, e.refs, e.finder, e.commits, releasePublishers(cmd, e, logger, ""), notifier, result),
+				logger,
+			)(param)
+			printResult(e, result, err, "Failed to add service tags")
 		}
 	}
 	tagAddCmd := &cobra.Command{
-		Use:   "add",
+		Use:   "add <version>",
 		Short: "add is a tool for multi service git tag manager",
-		Run:   addSyncAll(f(register), list, finder),
+		Args:  cobra.ExactArgs(1),
+		Run:   addSyncAll(f(e), e),
 	}
 	tagAddCmd.Flags().StringP("commit-id", "c", "", "Commit ID")
 	tagAddCmd.Flags().StringSliceP("services", "s", []string{}, "Add of services")
 	tagAddCmd.Flags().StringP("from-config-file", "f", "", "Add of services from config file")
 	tagAddCmd.Flags().Bool("sync", true, "Sync all service tags")
 	tagAddCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	tagAddCmd.Flags().Bool("allow-downgrade", false, "Allow versions lower than the latest tag of a service")
+	tagAddCmd.Flags().Bool("allow-skip", false, "Allow versions that are not the next patch, minor or major version")
+	addPushFlags(tagAddCmd)
+	addPublishFlags(tagAddCmd)
+	addNotifyFlag(tagAddCmd)
 	return tagAddCmd
 }
-func tagsPushCmd(logger *slog.Logger, getter usecase.CommitTagGetter, pusher usecase.CommitPusher) *cobra.Command {
-	f := func(getter usecase.CommitTagGetter, pusher usecase.CommitPusher) CobraCmdRunner {
+func tagsPushCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(e *executors) CobraCmdRunner {
 		return func(cmd *cobra.Command, args []string) {
+			result := newChangeList(e)
 			commitIdStr, _ := cmd.Flags().GetString("commit-id")
-			remoteStr, _ := cmd.Flags().GetString("remote")
+			remotes, _ := cmd.Flags().GetStringSlice("remote")
+			atomic, _ := cmd.Flags().GetBool("atomic")
+			if len(remotes) == 0 {
+				cfg, err := loadConfig(e)
+				if err != nil {
+					printResult(e, result, err, "Failed to load config")
+					return
+				}
+				remotes = cfg.Remotes
+			}
 
 			param := subcmd.PushCommandParameter{
-				CommitId: commitIdStr,
-				Remote:   remoteStr,
+				CommitId:         commitIdStr,
+				Remotes:          remotes,
+				Atomic:           atomic,
+				PublishParameter: publishParameter(cmd),
 			}
-			err := subcmd.LogSubCommandDecorator(
-				subcmd.PushCommand(getter, pusher),
-				logger,
-			)(param)
+			notifier, err := releaseNotifier(cmd, e, logger)
 			if err != nil {
-				fmt.Printf("Failed to push service tags: %s\n", err.Error())
+				printResult(e, result, err, "Failed to configure notifications")
 				return
 			}
+			err = subcmd.LogSubCommandDecorator(
+				subcmd.PushCommand(e.getter, e.refs, e.remoteRefs, e.pusher, e.commits, releasePublishers(cmd, e, logger, ""), notifier, result),
+				logger,
+			)(param)
+			printResult(e, result, err, "Failed to push service tags")
 		}
 	}
 	tagsPushCmd := &cobra.Command{
 		Use:   "push",
 		Short: "push is a tool for multi service git tag manager",
-		Run:   f(getter, pusher),
+		Args:  cobra.NoArgs,
+		Run:   f(e),
 	}
 	tagsPushCmd.Flags().StringP("commit-id", "c", "", "Commit ID")
-	tagsPushCmd.Flags().StringP("remote", "r", "", "Remote")
+	tagsPushCmd.Flags().StringSliceP("remote", "r", []string{}, "Remotes, the remotes of the config file or origin by default")
+	tagsPushCmd.Flags().Bool("atomic", false, "Update either all of the tags on a remote or none of them")
+	addPublishFlags(tagsPushCmd)
+	addNotifyFlag(tagsPushCmd)
 	return tagsPushCmd
 }
 
-func tagResetCmd(logger *slog.Logger, getter usecase.CommitTagGetter, localDestroyer usecase.DestroyServiceTags, remoteDestroyer usecase.DestroyServiceTags, list usecase.ListTags, finder usecase.CommitFinder) *cobra.Command {
+func tagResetCmd(logger *slog.Logger, e *executors) *cobra.Command {
 	f := func(cmd *cobra.Command, args []string) {
 		origin, _ := cmd.Flags().GetBool("origin")
 		excludeLocal, _ := cmd.Flags().GetBool("exclude-local")
 		commitIdStr, _ := cmd.Flags().GetString("commit-id")
+		force, _ := cmd.Flags().GetBool("force")
+		yes, _ := cmd.Flags().GetBool("yes")
+		result := newChangeList(e)
+		cfg, err := loadConfig(e)
+		if err != nil {
+			printResult(e, result, err, "Failed to load config")
+			return
+		}
+		lockedAfter, err := cfg.Protections.LockedAfterDuration()
+		if err != nil {
+			printResult(e, result, err, "Failed to load config")
+			return
+		}
 		param := subcmd.ResetCommandParameter{
 			Origin:       origin,
 			ExcludeLocal: excludeLocal,
 			CommitId:     commitIdStr,
+			Policy: usecase.ProtectionPolicy{
+				Services:    cfg.Protections.ServiceNames(),
+				LockedAfter: lockedAfter,
+			},
+			Force:  force,
+			Yes:    yes,
+			DryRun: e.plan != nil,
 		}
 		if len(args) > 0 {
 			param.CommitId = args[0]
 		}
 
-		err := subcmd.LogSubCommandDecorator(
-			subcmd.ResetCommand(getter, localDestroyer, remoteDestroyer),
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.ResetCommand(e.getter, e.localDestroyer, e.remoteDestroyer, e.refs, confirmOnTerminal, result),
 			logger,
 		)(param)
-		if err != nil {
-			fmt.Printf("Failed to reset service tags: %s\n", err.Error())
-		}
+		printResult(e, result, err, "Failed to reset service tags")
 	}
 	tagResetCmd := &cobra.Command{
 		Use:   "reset",
 		Short: "reset is a tool for multi service git tag manager",
-		Run:   addSyncAll(f, list, finder),
+		Run:   addSyncAll(f, e),
 	}
 	tagResetCmd.Flags().BoolP("origin", "o", false, "Reset origin")
 	tagResetCmd.Flags().BoolP("exclude-local", "e", false, "Exclude local")
 	tagResetCmd.Flags().StringP("state-file", "f", "services-state.yaml", "State file")
 	tagResetCmd.Flags().StringP("commit-id", "c", "", "Commit ID")
 	tagResetCmd.Flags().Bool("sync", true, "Sync all service tags")
+	tagResetCmd.Flags().Bool("force", false, "Allow deleting tags on origin")
+	tagResetCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation before deleting tags on origin")
 	return tagResetCmd
 }
 
-func tagVersionUpCmd(logger *slog.Logger, list usecase.ListTags, register usecase.RegisterServiceTags, getter usecase.CommitTagGetter, finder usecase.CommitFinder) *cobra.Command {
+func tagVersionUpCmd(logger *slog.Logger, e *executors) *cobra.Command {
 	f := func(cmd *cobra.Command, args []string) {
 		minor, _ := cmd.Flags().GetBool("minor")
 		major, _ := cmd.Flags().GetBool("major")
 		isAll, _ := cmd.Flags().GetBool("all")
 		commitIdStr, _ := cmd.Flags().GetString("commit-id")
 		services, _ := cmd.Flags().GetStringSlice("services")
+		remote, _ := cmd.Flags().GetString("remote")
 
 		param := subcmd.VersionUpCommandParameter{
-			Minor:    minor,
-			Major:    major,
-			IsAll:    isAll,
-			CommitId: commitIdStr,
-			Services: services,
+			Minor:            minor,
+			Major:            major,
+			IsAll:            isAll,
+			CommitId:         commitIdStr,
+			Services:         services,
+			Remote:           remote,
+			PushParameter:    pushParameter(cmd),
+			PublishParameter: publishParameter(cmd),
 		}
 
-		err := subcmd.LogSubCommandDecorator(
+		result := newChangeList(e)
+		register, err := versionFilesRegister(cmd, e)
+		if err != nil {
+			printResult(e, result, err, "Failed to load config")
+			return
+		}
+		notifier, err := releaseNotifier(cmd, e, logger)
+		if err != nil {
+			printResult(e, result, err, "Failed to configure notifications")
+			return
+		}
+		var versionsWriter usecase.WriteVersions
+		enabled, _ := cmd.Flags().GetBool("ci-output")
+		if enabled || cmd.Flags().Changed("ci-output-format") {
+			formats, _ := cmd.Flags().GetStringSlice("ci-output-format")
+			versionsWriter, err = ciOutputWriter(cmd, e, logger, formats)
+			if err != nil {
+				printResult(e, result, err, "Failed to configure CI outputs")
+				return
+			}
+		}
+		err = subcmd.LogSubCommandDecorator(
 			subcmd.VersionUpCommand(
-				list,
+				e.list,
 				register,
-				getter,
+				e.getter,
+				e.remoteRefs,
+				e.pusher,
+				e.localDestroyer,
+				e.refs,
+				e.commits,
+				releasePublishers(cmd, e, logger, ""),
+				notifier,
+				versionsWriter,
+				result,
 			),
 			logger,
 		)(param)
-
-		if err != nil {
-			fmt.Printf("Failed to version up all service tags: %s\n", err.Error())
-			return
-		}
+		printResult(e, result, err, "Failed to version up all service tags")
 	}
 	tagVersionUpCmd := &cobra.Command{
 		Use:   "upgrade",
 		Short: "version-up is a tool for multi service git tag manager",
-		Run:   addSyncAll(f, list, finder),
+		Args:  cobra.NoArgs,
+		Run:   addSyncAll(f, e),
 	}
 	tagVersionUpCmd.Flags().BoolP("minor", "m", false, "Minor version up")
 	tagVersionUpCmd.Flags().BoolP("major", "M", false, "Major version up")
 	tagVersionUpCmd.Flags().BoolP("all", "a", false, "Tag all services")
 	tagVersionUpCmd.Flags().StringP("commit-id", "c", "", "Commit ID")
 	tagVersionUpCmd.Flags().StringSliceP("services", "s", []string{}, "List of services")
+	tagVersionUpCmd.Flags().StringP("remote", "r", "", "Compute versions from the service tags of the remote")
 	tagVersionUpCmd.Flags().Bool("sync", true, "Sync all service tags")
 	tagVersionUpCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	addPushFlags(tagVersionUpCmd)
+	addPublishFlags(tagVersionUpCmd)
+	addNotifyFlag(tagVersionUpCmd)
+	tagVersionUpCmd.Flags().Bool("ci-output", false, "Write the created versions for the next CI jobs")
+	tagVersionUpCmd.Flags().StringSlice("ci-output-format", []string{autoFormat}, fmt.Sprintf("Formats of --ci-output (auto, %s), auto detects the CI, setting it implies --ci-output", joinFormats()))
+	addCIOutputFileFlags(tagVersionUpCmd)
+	tagVersionUpCmd.Flags().Bool("commit", false, "Commit the version files and tag the commit (release_commit.enabled of the config by default)")
 	return tagVersionUpCmd
 }
 
-type ServiceConfig struct {
-	Services []Service `yaml:"services"`
+// versionFilesRegister is the register of upgrade, which updates the version files of the config
+// and commits them with --commit before tagging.
+// The branch policy is checked first, so that no commit is made on a branch that can not be tagged.
+func versionFilesRegister(cmd *cobra.Command, e *executors) (usecase.RegisterServiceTags, error) {
+	cfg, err := loadConfig(e)
+	if err != nil {
+		return nil, err
+	}
+	files := []subcmd.VersionFile{}
+	for _, service := range cfg.Services {
+		for _, f := range service.VersionFiles {
+			files = append(files, subcmd.VersionFile{
+				Service: domain.ServiceName(service.Name),
+				File:    e.path(f.File),
+				JSON:    f.JSON,
+				TOML:    f.TOML,
+				Regex:   f.Regex,
+				Value:   f.Value,
+			})
+		}
+	}
+	commit := cfg.ReleaseCommit.Enabled
+	if cmd.Flags().Changed("commit") {
+		commit, _ = cmd.Flags().GetBool("commit")
+	}
+	if len(files) == 0 {
+		if commit {
+			return nil, fmt.Errorf("a release commit needs version_files of services")
+		}
+		return branchPolicyRegister(e, e.register)
+	}
+	var releaseCommit *subcmd.ReleaseCommit
+	if commit {
+		releaseCommit = &subcmd.ReleaseCommit{
+			Committer: e.committer,
+			Resetter:  e.commitResetter,
+			Finder:    e.finder,
+			Message:   cfg.ReleaseCommit.Message,
+		}
+	}
+	return branchPolicyRegister(e, subcmd.WithVersionFiles(e.register, files, e.files, e.refs, releaseCommit, textOutput(e)))
+}
+
+// branchPolicyRegister is the register of add and upgrade,
+// which only tags commits of the allowed branches of the config.
+func branchPolicyRegister(e *executors, register usecase.RegisterServiceTags) (usecase.RegisterServiceTags, error) {
+	cfg, err := loadConfig(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.AllowedBranches) == 0 {
+		return register, nil
+	}
+	return &usecase.BranchPolicyRegister{
+		Register:        register,
+		AllowedBranches: cfg.AllowedBranches,
+		Branches:        e.branches,
+		Ancestor:        e.ancestor,
+	}, nil
+}
+
+// addPushFlags adds the flags that push the tags created by add and upgrade.
+func addPushFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("push", false, "Push the created tags to the remote of --push-remote")
+	cmd.Flags().String("push-remote", string(domain.Origin), "Remote the created tags are pushed to, setting it implies --push")
+	cmd.Flags().Bool("atomic", false, "Push either all of the created tags or none of them")
+	cmd.Flags().Bool("rollback", false, "Delete the created tags locally when the push fails (implies --atomic)")
+}
+
+func pushParameter(cmd *cobra.Command) subcmd.PushParameter {
+	push := ""
+	if enabled, _ := cmd.Flags().GetBool("push"); enabled || cmd.Flags().Changed("push-remote") {
+		push, _ = cmd.Flags().GetString("push-remote")
+	}
+	atomic, _ := cmd.Flags().GetBool("atomic")
+	rollback, _ := cmd.Flags().GetBool("rollback")
+	return subcmd.PushParameter{
+		Push:     push,
+		Atomic:   atomic,
+		Rollback: rollback,
+	}
+}
+
+// addPublishFlags adds the flags that publish releases of the created or pushed tags.
+func addPublishFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("publish", false, "Publish a release of every created or pushed tag on the hosting service of the remote")
+	addAssetsFlag(cmd)
+}
+
+func addAssetsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("assets", []string{}, "Glob patterns of the files uploaded to every release, the assets of the config by default")
+}
+
+func publishParameter(cmd *cobra.Command) subcmd.PublishParameter {
+	publish, _ := cmd.Flags().GetBool("publish")
+	return subcmd.PublishParameter{
+		Publish: publish,
+	}
+}
+
+// releasePublishers returns the publishers of the hosting services the config selects per remote,
+// kind replaces the provider of the config when not empty.
+func releasePublishers(cmd *cobra.Command, e *executors, logger *slog.Logger, kind provider.Kind) subcmd.Publishers {
+	return func(remote string) (usecase.PublishRelease, error) {
+		cfg, err := loadConfig(e)
+		if err != nil {
+			return nil, err
+		}
+		release := cfg.ReleaseOf(remote)
+		target := provider.Target{
+			Kind:       provider.Kind(release.Provider),
+			BaseURL:    release.BaseURL,
+			Repository: release.Repository,
+		}
+		if kind != "" && kind != target.Kind {
+			// the base URL and the repository of another service do not apply
+			target = provider.Target{Kind: kind}
+		}
+		tokenEnv := release.TokenEnv
+		if tokenEnv == "" {
+			tokenEnv = provider.DefaultTokenEnv(target.Kind)
+		}
+		target.Token = os.Getenv(tokenEnv)
+		var publisher usecase.PublishRelease
+		publisher, err = provider.New(target)
+		if err != nil {
+			return nil, err
+		}
+		if e.plan != nil {
+			publisher = e.plan.Publisher(fmt.Sprintf("%s (%s)", remote, target.Kind))
+		} else if target.Token == "" {
+			return nil, fmt.Errorf("no %s token in $%s", target.Kind, tokenEnv)
+		}
+		publisher = &executor.LoggingCommandExecutor[usecase.PublishReleaseCommand]{
+			Executor: publisher,
+			Logger:   logger,
+		}
+		assets, _ := cmd.Flags().GetStringSlice("assets")
+		if len(assets) == 0 {
+			assets = release.Assets
+		}
+		patterns := []string{}
+		for _, asset := range assets {
+			patterns = append(patterns, e.path(asset))
+		}
+		return subcmd.WithAssets(publisher, patterns)
+	}
+}
+
+func addNotifyFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("notify", true, "Send a release event of every tag pushed to a remote to the notifications of the config")
+}
+
+// releaseNotifier returns the sinks of the notifications of the config, nil when there are none or --notify=false.
+func releaseNotifier(cmd *cobra.Command, e *executors, logger *slog.Logger) (usecase.NotifyRelease, error) {
+	if enabled, _ := cmd.Flags().GetBool("notify"); !enabled {
+		return nil, nil
+	}
+	cfg, err := loadConfig(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Notifications) == 0 {
+		return nil, nil
+	}
+	notifiers := usecase.Notifiers{}
+	for _, n := range cfg.Notifications {
+		var notifier usecase.NotifyRelease
+		switch n.Type {
+		case "webhook":
+			header := http.Header{}
+			for key, value := range n.Headers {
+				header.Set(key, os.ExpandEnv(value))
+			}
+			notifier = &notify.Webhook{URL: os.ExpandEnv(n.URL), Template: n.Template, Header: header}
+		case "slack":
+			notifier = &notify.Slack{URL: os.ExpandEnv(n.URL), Template: n.Template}
+		case "teams":
+			notifier = &notify.Teams{URL: os.ExpandEnv(n.URL), Template: n.Template}
+		case "email":
+			notifier = &notify.Email{
+				Addr:     n.SMTP,
+				From:     n.From,
+				To:       n.To,
+				Username: n.Username,
+				Password: os.ExpandEnv(n.Password),
+				Subject:  n.Subject,
+				Template: n.Template,
+			}
+		default:
+			return nil, fmt.Errorf("unknown notification type %s", n.Type)
+		}
+		if e.plan != nil {
+			notifier = e.plan.Notifier(n.Name())
+		} else {
+			attempts, delay, err := n.RetryPolicy()
+			if err != nil {
+				return nil, err
+			}
+			notifier = &notify.Retry{Notifier: notifier, Attempts: attempts, Delay: delay}
+		}
+		notifiers = append(notifiers, &executor.LoggingCommandExecutor[usecase.NotifyReleaseCommand]{
+			Executor: notifier,
+			Logger:   logger,
+		})
+	}
+	return notifiers, nil
+}
+
+// autoFormat selects the format of the CI msgtm runs on.
+const autoFormat = "auto"
+
+func joinFormats() string {
+	formats := []string{}
+	for _, format := range cioutput.Formats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ", ")
+}
+
+func addCIOutputFileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("dotenv-file", cioutput.DefaultDotenvFile, "Dotenv file of the gitlab format")
+	cmd.Flags().String("json-file", cioutput.DefaultJSONFile, "File of the json format")
+}
+
+// ciOutputWriter returns the writer of the versions in the formats.
+func ciOutputWriter(cmd *cobra.Command, e *executors, logger *slog.Logger, formats []string) (usecase.WriteVersions, error) {
+	dotenvFile, _ := cmd.Flags().GetString("dotenv-file")
+	jsonFile, _ := cmd.Flags().GetString("json-file")
+	writers := cioutput.Writers{}
+	for _, name := range formats {
+		format := cioutput.Format(name)
+		if name == autoFormat {
+			format = cioutput.Detect(os.Getenv)
+		}
+		var writer usecase.WriteVersions
+		target := ""
+		switch format {
+		case cioutput.GitHubActions:
+			writer = &cioutput.GitHub{OutputFile: os.Getenv("GITHUB_OUTPUT"), SummaryFile: os.Getenv("GITHUB_STEP_SUMMARY")}
+			target = "$GITHUB_OUTPUT"
+		case cioutput.GitLabCI:
+			target = e.path(dotenvFile)
+			writer = &cioutput.Dotenv{File: target}
+		case cioutput.AzurePipelines:
+			writer = &cioutput.Azure{Writer: sideOutput(e)}
+			target = "azure pipeline variables"
+		case cioutput.JSON:
+			target = e.path(jsonFile)
+			writer = &cioutput.JSONFile{File: target}
+		default:
+			return nil, fmt.Errorf("unknown CI output format %s, want one of auto, %s", name, joinFormats())
+		}
+		if e.plan != nil {
+			writer = e.plan.VersionsWriter(target)
+		}
+		writers = append(writers, &executor.LoggingCommandExecutor[usecase.WriteVersionsCommand]{
+			Executor: writer,
+			Logger:   logger,
+		})
+	}
+	return writers, nil
+}
+
+func manifestsCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	manifestsCmd := &cobra.Command{
+		Use:   "manifests",
+		Short: "manifests keeps the versions in deployment files in line with the service tags",
+	}
+	update := func(cmd *cobra.Command, args []string) {
+		services, _ := cmd.Flags().GetStringSlice("services")
+		check, _ := cmd.Flags().GetBool("check")
+		cfg, err := loadConfig(e)
+		if err != nil {
+			e.fail("Failed to load config", err)
+			return
+		}
+		mappings := []subcmd.ManifestMapping{}
+		for _, service := range cfg.Services {
+			for _, m := range service.Manifests {
+				value := m.Value
+				if value == "" {
+					value = cfg.Images.TargetTag
+				}
+				mappings = append(mappings, subcmd.ManifestMapping{
+					Service: domain.ServiceName(service.Name),
+					File:    e.path(m.File),
+					Path:    m.Path,
+					Image:   m.Image,
+					Value:   value,
+				})
+			}
+		}
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.ManifestsUpdateCommand(e.refs, e.files),
+			logger,
+		)(subcmd.ManifestsUpdateCommandParameter{
+			Mappings: mappings,
+			Services: services,
+			Check:    check,
+		})
+		if err != nil {
+			e.fail("Failed to update manifests", err)
+			return
+		}
+	}
+	updateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "update sets the versions in the manifests of the config to the latest service tags, keeping comments and formatting",
+		Run:   update,
+	}
+	updateCmd.Flags().StringSliceP("services", "s", []string{}, "Services, every service with manifests by default")
+	updateCmd.Flags().Bool("check", false, "Fail when a manifest is not up to date instead of writing it, e.g. in CI")
+	manifestsCmd.AddCommand(updateCmd)
+	return manifestsCmd
+}
+
+func ciOutputCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		services, _ := cmd.Flags().GetStringSlice("services")
+		remote, _ := cmd.Flags().GetString("remote")
+		formats, _ := cmd.Flags().GetStringSlice("format")
+		writer, err := ciOutputWriter(cmd, e, logger, formats)
+		if err != nil {
+			e.fail("Failed to configure CI outputs", err)
+			return
+		}
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.CIOutputCommand(e.refs, e.remoteRefs, writer),
+			logger,
+		)(subcmd.CIOutputCommandParameter{
+			Services: services,
+			Remote:   remote,
+		})
+		if err != nil {
+			e.fail("Failed to write CI outputs", err)
+			return
+		}
+	}
+	ciOutputCmd := &cobra.Command{
+		Use:   "ci-output",
+		Short: "ci-output writes the latest version of every service as variables of the CI pipeline, e.g. API_VERSION",
+		Run:   f,
+	}
+	ciOutputCmd.Flags().StringSlice("format", []string{autoFormat}, fmt.Sprintf("Formats (auto, %s), auto detects the CI", joinFormats()))
+	ciOutputCmd.Flags().StringSliceP("services", "s", []string{}, "Services, every service with a tag by default")
+	ciOutputCmd.Flags().StringP("remote", "r", "", "Read the versions from the service tags of the remote instead of the local ones")
+	addCIOutputFileFlags(ciOutputCmd)
+	return ciOutputCmd
+}
+
+func envCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		services, _ := cmd.Flags().GetStringSlice("services")
+		format, _ := cmd.Flags().GetString("format")
+		pkg, _ := cmd.Flags().GetString("ldflags-package")
+		param := subcmd.EnvCommandParameter{Services: services}
+		if fromState, _ := cmd.Flags().GetBool("from-state"); fromState {
+			fileName, _ := cmd.Flags().GetString("state-file")
+			param.StateFile = e.path(fileName)
+		}
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.EnvCommand(e.refs, &cioutput.Env{
+				Format:  cioutput.EnvFormat(format),
+				Writer:  os.Stdout,
+				Package: pkg,
+			}),
+			logger,
+		)(param)
+		if err != nil {
+			e.fail("Failed to print versions", err)
+			return
+		}
+	}
+	envCmd := &cobra.Command{
+		Use:         "env",
+		Short:       "env prints the latest version and commit of every service for builds, e.g. API_VERSION=1.2.3",
+		Run:         f,
+		Annotations: map[string]string{resultOnStdout: ""},
+	}
+	envCmd.Flags().String("format", string(cioutput.EnvDotenv), fmt.Sprintf("Format (%s)", joinEnvFormats()))
+	envCmd.Flags().StringSliceP("services", "s", []string{}, "Services, every service with a version by default")
+	envCmd.Flags().Bool("from-state", false, "Read the versions from the state file instead of the service tags")
+	envCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	envCmd.Flags().String("ldflags-package", cioutput.DefaultLdflagsPackage, "Go package of the version and commit variables of the ldflags format, which needs exactly one service")
+	return envCmd
+}
+
+func joinEnvFormats() string {
+	formats := []string{}
+	for _, format := range cioutput.EnvFormats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ", ")
+}
+
+func publishCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(kind provider.Kind) CobraCmdRunner {
+		return func(cmd *cobra.Command, args []string) {
+			commitIdStr, _ := cmd.Flags().GetString("commit-id")
+			services, _ := cmd.Flags().GetStringSlice("services")
+			remote, _ := cmd.Flags().GetString("remote")
+			err := subcmd.LogSubCommandDecorator(
+				subcmd.PublishCommand(e.getter, e.refs, e.remoteRefs, e.commits, releasePublishers(cmd, e, logger, kind)),
+				logger,
+			)(subcmd.PublishCommandParameter{
+				CommitId: commitIdStr,
+				Services: services,
+				Remote:   remote,
+			})
+			if err != nil {
+				e.fail("Failed to publish releases", err)
+				return
+			}
+		}
+	}
+	addFlags := func(cmd *cobra.Command) {
+		cmd.Flags().StringP("commit-id", "c", "", "Commit ID")
+		cmd.Flags().StringSliceP("services", "s", []string{}, "Services to publish, every service tag of the commit by default")
+		cmd.Flags().StringP("remote", "r", string(domain.Origin), "Remote whose hosting service and repository of the config are used")
+		addAssetsFlag(cmd)
+	}
+	publishCmd := &cobra.Command{
+		Use:   "publish [github|gitlab|gitea]",
+		Short: "publish creates a release with the changelog of every service tag of the commit on the hosting service of the remote",
+		Run:   f(""),
+	}
+	addFlags(publishCmd)
+	for _, kind := range provider.Kinds {
+		kindCmd := &cobra.Command{
+			Use:   string(kind),
+			Short: fmt.Sprintf("%s creates the releases on %s regardless of the provider of the config", kind, kind),
+			Run:   f(kind),
+		}
+		addFlags(kindCmd)
+		publishCmd.AddCommand(kindCmd)
+	}
+	return publishCmd
 }
 
-type Service struct {
-	Name string `yaml:"name"`
+func imagesCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	imagesCmd := &cobra.Command{
+		Use:   "images",
+		Short: "images manages the container images of the services in their registries",
+	}
+	retag := func(cmd *cobra.Command, args []string) {
+		commitIdStr, _ := cmd.Flags().GetString("commit-id")
+		services, _ := cmd.Flags().GetStringSlice("services")
+		cfg, err := loadConfig(e)
+		if err != nil {
+			e.fail("Failed to load config", err)
+			return
+		}
+		usernameEnv, passwordEnv := cfg.Images.CredentialEnvs()
+		client := &registry.Client{
+			PlainHTTP: cfg.Images.PlainHTTP,
+			Credentials: registry.FirstCredentials(
+				registry.EnvCredentials(usernameEnv, passwordEnv),
+				registry.DockerConfigCredentials(),
+			),
+		}
+		var copier usecase.CopyImage = &registry.ImageCopier{Client: client}
+		if e.plan != nil {
+			copier = e.plan.ImageCopier()
+		}
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.ImagesRetagCommand(
+				e.getter,
+				e.finder,
+				&executor.LoggingQueryExecutor[usecase.ImageExistsQuery, bool]{
+					Executor: &registry.ImageChecker{Client: client},
+					Logger:   logger,
+				},
+				&executor.LoggingCommandExecutor[usecase.CopyImageCommand]{
+					Executor: copier,
+					Logger:   logger,
+				},
+			),
+			logger,
+		)(subcmd.ImagesRetagCommandParameter{
+			CommitId: commitIdStr,
+			Services: services,
+			Naming:   cfg.Images.Naming(),
+		})
+		if err != nil {
+			e.fail("Failed to retag images", err)
+			return
+		}
+	}
+	retagCmd := &cobra.Command{
+		Use:   "retag",
+		Short: "retag tags the image built for the commit of every service tag with the version, e.g. api:<commit> as api:v1.2.3",
+		Run:   retag,
+	}
+	retagCmd.Flags().StringP("commit-id", "c", "", "Commit ID")
+	retagCmd.Flags().StringSliceP("services", "s", []string{}, "Services to retag, every service tag of the commit by default")
+	imagesCmd.AddCommand(retagCmd)
+	return imagesCmd
+}
+
+func pullCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		remote, _ := cmd.Flags().GetString("remote")
+		services, _ := cmd.Flags().GetStringSlice("services")
+		prefer, _ := cmd.Flags().GetString("prefer")
+		if len(services) == 0 {
+			cfg, err := loadConfig(e)
+			if err != nil {
+				e.fail("Failed to load config", err)
+				return
+			}
+			for _, service := range cfg.ServiceNames() {
+				services = append(services, service.String())
+			}
+		}
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.PullCommand(e.refs, e.remoteRefs, e.fetcher),
+			logger,
+		)(subcmd.PullCommandParameter{
+			Remote:   remote,
+			Services: services,
+			Prefer:   prefer,
+		})
+		if err != nil {
+			e.fail("Failed to pull service tags", err)
+			return
+		}
+	}
+	pullCmd := &cobra.Command{
+		Use:   "pull",
+		Short: "pull fetches the service tags of the remote and reconciles conflicting tags",
+		Run:   addSyncAll(f, e),
+	}
+	pullCmd.Flags().StringP("remote", "r", "", "Remote")
+	pullCmd.Flags().StringSliceP("services", "s", []string{}, "Services to pull, the services of the config file by default")
+	pullCmd.Flags().String("prefer", string(usecase.FailOnConflict), "Which tag wins when a tag points at different commits locally and on the remote (remote, local or fail)")
+	pullCmd.Flags().Bool("sync", false, "Sync all service tags")
+	pullCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	return pullCmd
+}
+
+func statusCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		fileName, _ := cmd.Flags().GetString("state-file")
+		result := output.NewStatusList()
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.StatusCommand(e.refs, result),
+			logger,
+		)(subcmd.StatusCommandParameter{
+			StateFile: e.path(fileName),
+		})
+		printResult(e, result, err, "Failed to get status")
+	}
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "status shows the latest tag of every service and whether the state file records it",
+		Run:   f,
+	}
+	statusCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	return statusCmd
+}
+
+func workspaceCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		fileName, _ := cmd.Flags().GetString("file")
+		backend, _ := cmd.Flags().GetString("backend")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		format, _ := cmd.Flags().GetString("output")
+		if output.Format(format).Structured() {
+			// the results of the repositories are not combined into one document
+			e.fail("Failed to run workspace command", fmt.Errorf("--output %s is not supported, use text or table", format))
+			return
+		}
+		globalArgs := []string{"--backend", backend, "--output", format}
+		if dryRun {
+			globalArgs = append(globalArgs, "--dry-run")
+		}
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.WorkspaceCommand(runInRepo(globalArgs)),
+			logger,
+		)(subcmd.WorkspaceCommandParameter{
+			File:   fileName,
+			Args:   args,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			e.fail("Failed to run workspace command", err)
+			return
+		}
+	}
+	workspaceCmd := &cobra.Command{
+		Use:         "workspace [list|status|upgrade|push] [flags]",
+		Annotations: map[string]string{withoutRepository: ""},
+		Short:       "workspace runs a command in every repository of the workspace file",
+		Args:        cobra.MinimumNArgs(1),
+		Run:         f,
+		// the repositories print their own plans in a dry run
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+	}
+	workspaceCmd.Flags().StringP("file", "w", config.DefaultWorkspaceFileName, "Workspace file")
+	// the flags after the command are the flags of the command
+	workspaceCmd.Flags().SetInterspersed(false)
+	return workspaceCmd
+}
+
+// runInRepo runs this executable with -C, so that every repository gets its own config and state file.
+func runInRepo(globalArgs []string) subcmd.RepoRunner {
+	return func(dir string, args []string, out io.Writer) error {
+		self, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		cmdArgs := append([]string{"-C", dir}, globalArgs...)
+		cmd := exec.Command(self, append(cmdArgs, args...)...)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		return cmd.Run()
+	}
+}
+
+func hooksCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "hooks installs git hooks that validate service tags",
+	}
+
+	install := func(cmd *cobra.Command, args []string) {
+		postMerge, _ := cmd.Flags().GetBool("post-merge")
+		force, _ := cmd.Flags().GetBool("force")
+		executable, err := os.Executable()
+		if err != nil {
+			e.fail("Failed to find msgtm executable", err)
+			return
+		}
+		hooksDir, err := e.hooksDir()
+		if err != nil {
+			e.fail("Failed to find hooks directory", err)
+			return
+		}
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.HooksInstallCommand(),
+			logger,
+		)(subcmd.HooksInstallCommandParameter{
+			HooksDir:   hooksDir,
+			Executable: executable,
+			PostMerge:  postMerge,
+			Force:      force,
+		})
+		if err != nil {
+			e.fail("Failed to install hooks", err)
+			return
+		}
+	}
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "install adds a pre-push hook that rejects invalid service tags, and optionally a post-merge hook that syncs the state file",
+		Run:   install,
+	}
+	installCmd.Flags().Bool("post-merge", false, "Install a post-merge hook that runs sync")
+	installCmd.Flags().Bool("force", false, "Overwrite hooks that were not installed by msgtm")
+
+	prePush := func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig(e)
+		if err != nil {
+			e.fail("Failed to load config", err)
+			return
+		}
+		err = subcmd.LogSubCommandDecorator(
+			subcmd.PrePushCommand(e.remoteRefs, e.finder, e.ancestor),
+			logger,
+		)(subcmd.PrePushCommandParameter{
+			Remote:          args[0],
+			Refs:            os.Stdin,
+			Services:        cfg.ServiceNames(),
+			ProtectedBranch: cfg.Hooks.ProtectedBranchOrDefault(),
+		})
+		if err != nil {
+			fmt.Fprintln(sideOutput(e), err.Error())
+			e.exitCode = 1
+		}
+	}
+	prePushCmd := &cobra.Command{
+		Use:   "pre-push <remote> [url]",
+		Short: "pre-push validates the service tags of a push, it is run by the pre-push hook",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   prePush,
+	}
+
+	hooksCmd.AddCommand(installCmd)
+	hooksCmd.AddCommand(prePushCmd)
+	return hooksCmd
+}
+
+// confirmOnTerminal asks the question on the standard input, anything but y or yes is no.
+func confirmOnTerminal(question string) (bool, error) {
+	// stdout is the result, e.g. JSON read by scripts
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("no confirmation, use --yes to skip it: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// newChangeList is the result of a command creating, deleting or pushing tags.
+func newChangeList(e *executors) *output.ChangeList {
+	result := output.NewChangeList()
+	result.DryRun = e.plan != nil
+	return result
+}
+
+// printResult prints the result of a command in the --output format.
+// The error of the command is a field of JSON and YAML, and follows the result in text and tables,
+// and msgtm exits with 1 once the state file is synced.
+func printResult(e *executors, result output.Result, err error, failure string) {
+	if err != nil {
+		result.Fail(err)
+	}
+	if perr := output.Print(os.Stdout, e.output, result); perr != nil {
+		fmt.Fprintf(sideOutput(e), "Failed to print the result: %s\n", perr.Error())
+	}
+	if err == nil {
+		return
+	}
+	if !e.output.Structured() {
+		fmt.Printf("%s: %s\n", failure, err.Error())
+	}
+	e.exitCode = 1
+}
+
+// fail prints the error of a command without a result and makes msgtm exit with 1.
+func (e *executors) fail(failure string, err error) {
+	fmt.Fprintf(sideOutput(e), "%s: %s\n", failure, err.Error())
+	e.exitCode = 1
+}
+
+// textOutput is stdout for the messages commands print besides their results, which JSON and YAML leave out.
+func textOutput(e *executors) io.Writer {
+	if e.output.Structured() {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// sideOutput is stdout for the errors and CI commands besides the results, and stderr when scripts read JSON or YAML from stdout.
+func sideOutput(e *executors) io.Writer {
+	if e.output.Structured() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// logWriter is the writer of the logs, which move to stderr when scripts read the results from stdout.
+type logWriter struct {
+	io.Writer
+}
+
+func loadConfig(e *executors) (*config.Config, error) {
+	return config.Load(e.configFile)
+}
+
+func schemaCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Fprintf(sideOutput(e), "Error: schema command must kind args. (%s or %s)\n", subcmd.StateKind, subcmd.ConfigKind)
+			e.exitCode = 1
+			return
+		}
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.SchemaCommand(),
+			logger,
+		)(subcmd.SchemaCommandParameter{
+			Kind: args[0],
+		})
+		if err != nil {
+			e.fail("Failed to print schema", err)
+		}
+	}
+	schemaCmd := &cobra.Command{
+		Use:         "schema [state|config|workspace]",
+		Annotations: map[string]string{withoutRepository: ""},
+		Short:       "schema prints the JSON Schema of the state file, the config file or the workspace file",
+		Run:         f,
+	}
+	return schemaCmd
+}
+
+func validateCmd(logger *slog.Logger, e *executors) *cobra.Command {
+	f := func(cmd *cobra.Command, args []string) {
+		kind := subcmd.StateKind
+		if len(args) > 0 {
+			kind = args[0]
+		}
+		fileName, _ := cmd.Flags().GetString("file")
+		if fileName == "" && kind == subcmd.StateKind {
+			fileName, _ = cmd.Flags().GetString("state-file")
+		}
+		if fileName == "" && kind == subcmd.WorkspaceKind {
+			fileName = config.DefaultWorkspaceFileName
+		}
+		if fileName == "" {
+			fileName = e.configFile
+		} else {
+			fileName = e.path(fileName)
+		}
+		err := subcmd.LogSubCommandDecorator(
+			subcmd.ValidateCommand(),
+			logger,
+		)(subcmd.ValidateCommandParameter{
+			Kind: kind,
+			File: fileName,
+		})
+		if err != nil {
+			fmt.Fprintf(sideOutput(e), "Failed to validate %s file:\n%s\n", kind, err.Error())
+			e.exitCode = 1
+		}
+	}
+	validateCmd := &cobra.Command{
+		Use:         "validate [state|config|workspace]",
+		Annotations: map[string]string{withoutRepository: ""},
+		Short:       "validate checks the state file, the config file or the workspace file against its schema",
+		Run:         f,
+	}
+	validateCmd.Flags().StringP("file", "f", "", "File to validate")
+	validateCmd.Flags().StringP("state-file", "t", "services-state.yaml", "State file")
+	return validateCmd
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
+	"msgtm/pkg/usecase"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type stubTagRefs []domain.TagRef
+
+func (s stubTagRefs) Execute(usecase.ListTagRefsQuery) (*[]domain.TagRef, error) {
+	refs := []domain.TagRef(s)
+	return &refs, nil
+}
+
+func TestAddSyncAllSyncsAfterFailedPush(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "services-state.yaml")
+	err := os.WriteFile(fileName, []byte("services:\n- name: api\n  latest: null\n  prev: null\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &executors{
+		refs:   stubTagRefs{{Tag: "api-v1.0.0", CommitId: "abc"}},
+		output: output.Text,
+	}
+	// the tag is created, then the push fails
+	push := func(cmd *cobra.Command, args []string) {
+		printResult(e, output.NewChangeList(), errors.New("rejected"), "Failed to push service tags")
+	}
+	cmd := &cobra.Command{Run: addSyncAll(push, e)}
+	cmd.Flags().Bool("sync", true, "")
+	cmd.Flags().String("state-file", fileName, "")
+
+	cmd.Run(cmd, nil)
+
+	if e.exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", e.exitCode)
+	}
+	state, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(state), "abc") {
+		t.Errorf("state file is not synced:\n%s", state)
+	}
+}
@@ -0,0 +1,214 @@
+// Package cioutput hands the versions of services to the following jobs of CI pipelines,
+// in the formats the CI services read natively.
+package cioutput
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"os"
+	"strings"
+)
+
+type Format string
+
+const (
+	// GitHubActions appends the variables to $GITHUB_OUTPUT and a table to $GITHUB_STEP_SUMMARY.
+	GitHubActions Format = "github"
+	// GitLabCI writes a dotenv file, to be declared as artifacts:reports:dotenv.
+	GitLabCI Format = "gitlab"
+	// AzurePipelines prints ##vso[task.setvariable] logging commands.
+	AzurePipelines Format = "azure"
+	// JSON writes the versions to a file.
+	JSON Format = "json"
+)
+
+var Formats = []Format{GitHubActions, GitLabCI, AzurePipelines, JSON}
+
+const (
+	DefaultDotenvFile = "msgtm.env"
+	DefaultJSONFile   = "msgtm-versions.json"
+)
+
+// Writers writes the versions in several formats, stopping at the first failure.
+type Writers []usecase.WriteVersions
+
+func (w Writers) Execute(cmd usecase.WriteVersionsCommand) error {
+	for _, writer := range w {
+		if err := writer.Execute(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Detect returns the format of the CI service msgtm runs on, JSON outside of a known CI.
+func Detect(getenv func(string) string) Format {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		return GitHubActions
+	case getenv("GITLAB_CI") == "true":
+		return GitLabCI
+	case getenv("TF_BUILD") == "True":
+		return AzurePipelines
+	}
+	return JSON
+}
+
+func variables(versions []domain.ServiceVersion) []domain.Variable {
+	result := []domain.Variable{}
+	for _, version := range versions {
+		result = append(result, version.Variables()...)
+	}
+	return result
+}
+
+func appendFile(name string, write func(w io.Writer) error) error {
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeFile(name string, write func(w io.Writer) error) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GitHub writes step outputs and the job summary of GitHub Actions, see
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-output-parameter
+type GitHub struct {
+	// OutputFile is $GITHUB_OUTPUT.
+	OutputFile string
+	// SummaryFile is $GITHUB_STEP_SUMMARY, no summary is written when empty.
+	SummaryFile string
+}
+
+func (g *GitHub) Execute(cmd usecase.WriteVersionsCommand) error {
+	if g.OutputFile == "" {
+		return fmt.Errorf("$GITHUB_OUTPUT is not set, not running on GitHub Actions")
+	}
+	err := appendFile(g.OutputFile, func(w io.Writer) error {
+		for _, variable := range variables(cmd.Versions) {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", variable.Name, variable.Value); err != nil {
+				return err
+			}
+		}
+		// versions is a JSON array for fromJSON, e.g. a matrix of the released services
+		data, err := json.Marshal(cmd.Versions)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "versions=%s\n", data)
+		return err
+	})
+	if err != nil || g.SummaryFile == "" {
+		return err
+	}
+	return appendFile(g.SummaryFile, func(w io.Writer) error {
+		_, err := io.WriteString(w, Summary(cmd.Versions))
+		return err
+	})
+}
+
+// Summary renders the versions as a Markdown table.
+func Summary(versions []domain.ServiceVersion) string {
+	b := &strings.Builder{}
+	b.WriteString("### Service versions\n\n")
+	if len(versions) == 0 {
+		b.WriteString("No service versions.\n")
+		return b.String()
+	}
+	b.WriteString("| Service | Version | Tag | Commit |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, version := range versions {
+		commit := string(version.Commit)
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | `%s` |\n", version.Service, version.Version, version.Tag, commit)
+	}
+	return b.String()
+}
+
+// Dotenv writes a dotenv file, e.g. the dotenv report of GitLab CI, see
+// https://docs.gitlab.com/ee/ci/yaml/artifacts_reports.html#artifactsreportsdotenv
+type Dotenv struct {
+	File string
+}
+
+func (d *Dotenv) Execute(cmd usecase.WriteVersionsCommand) error {
+	return writeFile(d.File, func(w io.Writer) error {
+		return WriteDotenv(w, variables(cmd.Versions))
+	})
+}
+
+// WriteDotenv writes NAME=value lines, the values of versions, tags and commits never need quoting.
+func WriteDotenv(w io.Writer, variables []domain.Variable) error {
+	for _, variable := range variables {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", variable.Name, variable.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Azure prints the logging commands that set output variables of Azure Pipelines, see
+// https://learn.microsoft.com/en-us/azure/devops/pipelines/scripts/logging-commands#setvariable-initialize-or-modify-the-value-of-a-variable
+type Azure struct {
+	Writer io.Writer
+}
+
+func (a *Azure) Execute(cmd usecase.WriteVersionsCommand) error {
+	for _, variable := range variables(cmd.Versions) {
+		_, err := fmt.Fprintf(a.Writer, "##vso[task.setvariable variable=%s;isOutput=true]%s\n", variable.Name, variable.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONFile writes the versions and their variables, e.g.
+// {"services": [{"service": "api", "version": "1.2.3", ...}], "variables": {"API_VERSION": "1.2.3", ...}}
+type JSONFile struct {
+	File string
+}
+
+type document struct {
+	Services  []domain.ServiceVersion `json:"services"`
+	Variables map[string]string       `json:"variables"`
+}
+
+func newDocument(cmd usecase.WriteVersionsCommand) document {
+	doc := document{Services: cmd.Versions, Variables: map[string]string{}}
+	if doc.Services == nil {
+		doc.Services = []domain.ServiceVersion{}
+	}
+	for _, variable := range variables(cmd.Versions) {
+		doc.Variables[variable.Name] = variable.Value
+	}
+	return doc
+}
+
+func (j *JSONFile) Execute(cmd usecase.WriteVersionsCommand) error {
+	return writeFile(j.File, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newDocument(cmd))
+	})
+}
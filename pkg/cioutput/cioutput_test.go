@@ -0,0 +1,131 @@
+package cioutput_test
+
+import (
+	"encoding/json"
+	"msgtm/pkg/cioutput"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var versions = usecase.WriteVersionsCommand{Versions: []domain.ServiceVersion{
+	{Service: "api", Version: "1.2.3", Tag: "api-v1.2.3", Commit: "0123456789abcdef"},
+	{Service: "user-web", Version: "0.1.0", Tag: "user-web-v0.1.0", Commit: "fedcba9876543210"},
+}}
+
+func read(t *testing.T, name string) string {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGitHub(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	summary := filepath.Join(dir, "summary")
+	// outputs of earlier steps are kept
+	os.WriteFile(output, []byte("earlier=1\n"), 0o644)
+
+	err := (&cioutput.GitHub{OutputFile: output, SummaryFile: summary}).Execute(versions)
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	got := read(t, output)
+	for _, want := range []string{"earlier=1\n", "API_VERSION=1.2.3\n", "API_TAG=api-v1.2.3\n", "USER_WEB_COMMIT=fedcba9876543210\n", `versions=[{"service":"api",`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("$GITHUB_OUTPUT does not contain %q:\n%s", want, got)
+		}
+	}
+	if want := "| user-web | 0.1.0 | user-web-v0.1.0 | `fedcba9` |\n"; !strings.Contains(read(t, summary), want) {
+		t.Errorf("summary does not contain %q:\n%s", want, read(t, summary))
+	}
+
+	if err := (&cioutput.GitHub{}).Execute(versions); err == nil {
+		t.Error("Execute() without $GITHUB_OUTPUT error = nil, want an error")
+	}
+}
+
+func TestDotenvAzureAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, "msgtm.env")
+	if err := (&cioutput.Dotenv{File: dotenv}).Execute(versions); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(read(t, dotenv), "\n")[0]; got != "API_VERSION=1.2.3" {
+		t.Errorf("first line of dotenv = %s, want API_VERSION=1.2.3", got)
+	}
+
+	b := &strings.Builder{}
+	if err := (&cioutput.Azure{Writer: b}).Execute(versions); err != nil {
+		t.Fatal(err)
+	}
+	if want := "##vso[task.setvariable variable=API_VERSION;isOutput=true]1.2.3\n"; !strings.HasPrefix(b.String(), want) {
+		t.Errorf("azure = %s, want to start with %s", b.String(), want)
+	}
+
+	file := filepath.Join(dir, "versions.json")
+	if err := (&cioutput.JSONFile{File: file}).Execute(versions); err != nil {
+		t.Fatal(err)
+	}
+	doc := struct {
+		Services  []domain.ServiceVersion `json:"services"`
+		Variables map[string]string       `json:"variables"`
+	}{}
+	if err := json.Unmarshal([]byte(read(t, file)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Services) != 2 || doc.Variables["USER_WEB_VERSION"] != "0.1.0" {
+		t.Errorf("json = %+v, want 2 services and USER_WEB_VERSION", doc)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := map[string]string{"GITLAB_CI": "true"}
+	if got := cioutput.Detect(func(key string) string { return env[key] }); got != cioutput.GitLabCI {
+		t.Errorf("Detect() = %s, want gitlab", got)
+	}
+	if got := cioutput.Detect(func(string) string { return "" }); got != cioutput.JSON {
+		t.Errorf("Detect() outside of CI = %s, want json", got)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	tests := []struct {
+		format cioutput.EnvFormat
+		want   string
+	}{
+		{cioutput.EnvDotenv, "API_VERSION=1.2.3\n"},
+		{cioutput.EnvShell, "export API_VERSION='1.2.3'\n"},
+		{cioutput.EnvMake, "API_VERSION := 1.2.3\n"},
+		{cioutput.EnvJSON, `"API_VERSION": "1.2.3"`},
+	}
+	for _, tt := range tests {
+		b := &strings.Builder{}
+		if err := (&cioutput.Env{Format: tt.format, Writer: b}).Execute(versions); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), tt.want) {
+			t.Errorf("%s = %s, want to contain %s", tt.format, b.String(), tt.want)
+		}
+	}
+	if err := (&cioutput.Env{Format: "toml", Writer: &strings.Builder{}}).Execute(versions); err == nil {
+		t.Error("unknown format error = nil, want an error")
+	}
+
+	b := &strings.Builder{}
+	one := usecase.WriteVersionsCommand{Versions: versions.Versions[:1]}
+	if err := (&cioutput.Env{Format: cioutput.EnvLdflags, Writer: b}).Execute(one); err != nil {
+		t.Fatal(err)
+	}
+	if want := "-X main.version=1.2.3 -X main.commit=0123456789abcdef\n"; b.String() != want {
+		t.Errorf("ldflags = %q, want %q", b.String(), want)
+	}
+	if err := (&cioutput.Env{Format: cioutput.EnvLdflags, Writer: &strings.Builder{}}).Execute(versions); err == nil {
+		t.Error("ldflags of 2 services error = nil, want an error")
+	}
+}
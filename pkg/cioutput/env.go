@@ -0,0 +1,79 @@
+package cioutput
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"msgtm/pkg/usecase"
+	"strings"
+)
+
+// EnvFormat is a format of msgtm env, read by shells and build tools rather than CI services.
+type EnvFormat string
+
+const (
+	// EnvDotenv prints NAME=value lines, e.g. for docker run --env-file or docker compose.
+	EnvDotenv EnvFormat = "dotenv"
+	// EnvShell prints export statements, e.g. for eval "$(msgtm env --format shell)".
+	EnvShell EnvFormat = "shell"
+	// EnvMake prints variable assignments for include in a Makefile.
+	EnvMake EnvFormat = "make"
+	// EnvJSON prints the versions and their variables like the json format of ci-output.
+	EnvJSON EnvFormat = "json"
+	// EnvLdflags prints the -X flags of go build -ldflags of exactly one service,
+	// the variables are not named after the service, so the flags of several services would overwrite each other.
+	EnvLdflags EnvFormat = "ldflags"
+)
+
+var EnvFormats = []EnvFormat{EnvDotenv, EnvShell, EnvMake, EnvJSON, EnvLdflags}
+
+const DefaultLdflagsPackage = "main"
+
+// Env prints the versions to Writer, so that builds stamp them without scripts of their own.
+type Env struct {
+	Format EnvFormat
+	Writer io.Writer
+	// Package is the Go package of the version and commit variables of ldflags, DefaultLdflagsPackage when empty.
+	Package string
+}
+
+func (e *Env) Execute(cmd usecase.WriteVersionsCommand) error {
+	switch e.Format {
+	case EnvDotenv:
+		return WriteDotenv(e.Writer, variables(cmd.Versions))
+	case EnvShell:
+		for _, variable := range variables(cmd.Versions) {
+			if _, err := fmt.Fprintf(e.Writer, "export %s=%s\n", variable.Name, shellQuote(variable.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case EnvMake:
+		for _, variable := range variables(cmd.Versions) {
+			if _, err := fmt.Fprintf(e.Writer, "%s := %s\n", variable.Name, variable.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case EnvJSON:
+		encoder := json.NewEncoder(e.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(newDocument(cmd))
+	case EnvLdflags:
+		pkg := e.Package
+		if pkg == "" {
+			pkg = DefaultLdflagsPackage
+		}
+		if len(cmd.Versions) != 1 {
+			return fmt.Errorf("the %s format prints the version of one service, not of %d services", EnvLdflags, len(cmd.Versions))
+		}
+		version := cmd.Versions[0]
+		_, err := fmt.Fprintf(e.Writer, "-X %s.version=%s -X %s.commit=%s\n", pkg, version.Version, pkg, version.Commit)
+		return err
+	}
+	return fmt.Errorf("unknown format %s", e.Format)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
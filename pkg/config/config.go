@@ -0,0 +1,344 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/schema"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const DefaultFileName = "msgtm.yaml"
+
+// Config is the user written configuration of msgtm.
+type Config struct {
+	Services []Service `json:"services" yaml:"services"`
+	// Remotes are the remotes service tags are pushed to.
+	Remotes []string `json:"remotes" yaml:"remotes"`
+	// Repo is the repository to operate on, relative to the config file.
+	// The -C/--repo flag takes precedence.
+	Repo string `json:"repo" yaml:"repo"`
+	// Hooks configures the git hooks installed by msgtm hooks install.
+	Hooks Hooks `json:"hooks" yaml:"hooks"`
+	// Protections limits the service tags reset can delete.
+	Protections Protections `json:"protections" yaml:"protections"`
+	// AllowedBranches are the branches add and upgrade can tag commits of, e.g. main or release/*.
+	// Local and remote-tracking branches are checked, any commit can be tagged when empty.
+	AllowedBranches []string `json:"allowed_branches" yaml:"allowed_branches"`
+	// Releases selects the hosting service releases are published on per remote.
+	Releases []Release `json:"releases" yaml:"releases"`
+	// GitHub configures the GitHub releases of the remotes that are not in Releases.
+	GitHub GitHub `json:"github" yaml:"github"`
+	// Images configures the container images retagged by images retag.
+	Images Images `json:"images" yaml:"images"`
+	// Notifications are the sinks of the release events of add, upgrade and push.
+	Notifications []Notification `json:"notifications" yaml:"notifications"`
+	// ReleaseCommit commits the version files of the services before upgrade tags the commit.
+	ReleaseCommit ReleaseCommit `json:"release_commit" yaml:"release_commit"`
+}
+
+// Notification is a sink of release events.
+// ${VAR} in the URL, the headers and the SMTP password is replaced by the environment variable,
+// so that the config file never holds secrets.
+type Notification struct {
+	Type string `json:"type" yaml:"type" jsonschema:"required,enum=webhook|slack|teams|email"`
+	// URL is the webhook of webhook, slack and teams, e.g. ${SLACK_WEBHOOK_URL}.
+	URL string `json:"url" yaml:"url"`
+	// Headers are added to the requests of webhook, e.g. Authorization: Bearer ${TOKEN}.
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// Template is the text/template of the body of webhook, of the message of slack and teams, and of the mail of email.
+	// The fields are the ones of the JSON of an event, e.g. {{.Service}}, {{.Version}} and {{.Notes}}.
+	Template string `json:"template" yaml:"template"`
+	// SMTP is the host and port of the mail server of email, e.g. smtp.example.com:587.
+	SMTP     string   `json:"smtp" yaml:"smtp"`
+	From     string   `json:"from" yaml:"from"`
+	To       []string `json:"to" yaml:"to"`
+	Username string   `json:"username" yaml:"username"`
+	Password string   `json:"password" yaml:"password"`
+	// Subject is the template of the subject of email.
+	Subject string `json:"subject" yaml:"subject"`
+	// Retries is the number of times a failed event is sent again, 2 by default.
+	Retries *int `json:"retries" yaml:"retries"`
+	// RetryDelay is the wait before the first retry, doubled after every retry, 1s by default.
+	RetryDelay string `json:"retry_delay" yaml:"retry_delay" jsonschema:"pattern=^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$"`
+}
+
+const (
+	DefaultNotificationRetries    = 2
+	DefaultNotificationRetryDelay = time.Second
+)
+
+// Name describes the sink in logs and dry runs, e.g. slack hooks.slack.com.
+func (n *Notification) Name() string {
+	if n.Type == "email" {
+		return fmt.Sprintf("email to %s", strings.Join(n.To, ", "))
+	}
+	// the path of a chat webhook is a secret
+	if u, err := url.Parse(os.ExpandEnv(n.URL)); err == nil && u.Host != "" {
+		return fmt.Sprintf("%s %s", n.Type, u.Host)
+	}
+	return n.Type
+}
+
+// RetryPolicy returns the number of attempts and the first delay between them.
+func (n *Notification) RetryPolicy() (int, time.Duration, error) {
+	retries := DefaultNotificationRetries
+	if n.Retries != nil {
+		retries = *n.Retries
+	}
+	delay := DefaultNotificationRetryDelay
+	if n.RetryDelay != "" {
+		d, err := time.ParseDuration(n.RetryDelay)
+		if err != nil {
+			return 0, 0, fmt.Errorf("notifications.retry_delay: %w", err)
+		}
+		delay = d
+	}
+	return retries + 1, delay, nil
+}
+
+type Images struct {
+	// Registry is the registry and namespace of the images, e.g. registry.example.com/platform.
+	// The image of a service is <registry>/<service> unless it is in Services.
+	Registry string `json:"registry" yaml:"registry"`
+	// SourceTag is the template of the tag images are built with, {{.Commit}} by default.
+	// The fields are Service, Version, SemVer, Commit and ShortCommit.
+	SourceTag string `json:"source_tag" yaml:"source_tag"`
+	// TargetTag is the template of the tag of the version, {{.Version}} by default.
+	TargetTag string `json:"target_tag" yaml:"target_tag"`
+	// PlainHTTP talks to the registries over HTTP instead of HTTPS, e.g. to a local registry.
+	PlainHTTP bool `json:"plain_http" yaml:"plain_http"`
+	// UsernameEnv and PasswordEnv are the environment variables of the credentials of the registries,
+	// REGISTRY_USERNAME and REGISTRY_PASSWORD by default. The auths of docker login are used without them.
+	UsernameEnv string `json:"username_env" yaml:"username_env"`
+	PasswordEnv string `json:"password_env" yaml:"password_env"`
+	// Services are the images of services that are not named after the service.
+	Services []ServiceImage `json:"services" yaml:"services"`
+}
+
+type ServiceImage struct {
+	Name  string `json:"name" yaml:"name" jsonschema:"required,pattern=^[a-zA-Z0-9-]+$"`
+	Image string `json:"image" yaml:"image" jsonschema:"required"`
+}
+
+const (
+	DefaultRegistryUsernameEnv = "REGISTRY_USERNAME"
+	DefaultRegistryPasswordEnv = "REGISTRY_PASSWORD"
+)
+
+// Naming returns the names of the images of services.
+func (i *Images) Naming() *domain.ImageNaming {
+	images := map[domain.ServiceName]string{}
+	for _, service := range i.Services {
+		images[domain.ServiceName(service.Name)] = service.Image
+	}
+	return &domain.ImageNaming{
+		Registry:  i.Registry,
+		Images:    images,
+		SourceTag: i.SourceTag,
+		TargetTag: i.TargetTag,
+	}
+}
+
+// CredentialEnvs returns the environment variables of the username and the password of the registries.
+func (i *Images) CredentialEnvs() (string, string) {
+	username, password := i.UsernameEnv, i.PasswordEnv
+	if username == "" {
+		username = DefaultRegistryUsernameEnv
+	}
+	if password == "" {
+		password = DefaultRegistryPasswordEnv
+	}
+	return username, password
+}
+
+// Release is the hosting service of a remote.
+// The base URL, the repository and the token default to the environment of the CI of the service.
+type Release struct {
+	Remote   string `json:"remote" yaml:"remote" jsonschema:"required"`
+	Provider string `json:"provider" yaml:"provider" jsonschema:"required,enum=github|gitlab|gitea"`
+	// BaseURL is the REST API, e.g. https://gitlab.example.com/api/v4.
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	// Repository is the path of the repository, e.g. group/subgroup/monorepo.
+	Repository string `json:"repository" yaml:"repository"`
+	// TokenEnv is the environment variable of the token, the config file never holds it.
+	TokenEnv string `json:"token_env" yaml:"token_env"`
+	// Assets are glob patterns of the files uploaded to every release, relative to the repository.
+	Assets []string `json:"assets" yaml:"assets"`
+}
+
+type GitHub struct {
+	// BaseURL is the REST API, e.g. https://github.example.com/api/v3 for GitHub Enterprise.
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	// Repository is the owner and name of the repository.
+	Repository string `json:"repository" yaml:"repository" jsonschema:"pattern=^[^/]+/[^/]+$"`
+	// TokenEnv is the environment variable of the token, the config file never holds it.
+	TokenEnv string `json:"token_env" yaml:"token_env"`
+	// Assets are glob patterns of the files uploaded to every release, relative to the repository.
+	Assets []string `json:"assets" yaml:"assets"`
+}
+
+const DefaultReleaseProvider = "github"
+
+// ReleaseOf returns the hosting service of the remote, the github section when the remote is not in Releases.
+func (c *Config) ReleaseOf(remote string) Release {
+	for _, release := range c.Releases {
+		if release.Remote == remote {
+			return release
+		}
+	}
+	return Release{
+		Remote:     remote,
+		Provider:   DefaultReleaseProvider,
+		BaseURL:    c.GitHub.BaseURL,
+		Repository: c.GitHub.Repository,
+		TokenEnv:   c.GitHub.TokenEnv,
+		Assets:     c.GitHub.Assets,
+	}
+}
+
+type Protections struct {
+	// Services are the services whose tags can never be deleted.
+	Services []string `json:"services" yaml:"services"`
+	// LockedAfter is the age after which annotated tags can not be deleted, e.g. 72h.
+	// Lightweight tags are never locked, their date is the date of the commit, not of the tag.
+	LockedAfter string `json:"locked_after" yaml:"locked_after" jsonschema:"pattern=^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$"`
+}
+
+func (p *Protections) ServiceNames() []domain.ServiceName {
+	names := make([]domain.ServiceName, 0, len(p.Services))
+	for _, service := range p.Services {
+		names = append(names, domain.ServiceName(service))
+	}
+	return names
+}
+
+// LockedAfterDuration parses LockedAfter, it is zero when not set.
+func (p *Protections) LockedAfterDuration() (time.Duration, error) {
+	if p.LockedAfter == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.LockedAfter)
+	if err != nil {
+		return 0, fmt.Errorf("protections.locked_after: %w", err)
+	}
+	return d, nil
+}
+
+type Hooks struct {
+	// ProtectedBranch is the branch the commits of pushed service tags must be on,
+	// either locally or on the remote. It is main by default.
+	ProtectedBranch string `json:"protected_branch" yaml:"protected_branch"`
+}
+
+const DefaultProtectedBranch = "main"
+
+// ProtectedBranchOrDefault returns the protected branch of the pre-push hook.
+func (h *Hooks) ProtectedBranchOrDefault() string {
+	if h.ProtectedBranch == "" {
+		return DefaultProtectedBranch
+	}
+	return h.ProtectedBranch
+}
+
+type Service struct {
+	Name string `json:"name" yaml:"name" jsonschema:"required,pattern=^[a-zA-Z0-9-]+$"`
+	// Manifests are the values of deployment files manifests update sets to the latest version of the service.
+	Manifests []Manifest `json:"manifests" yaml:"manifests"`
+	// VersionFiles are the files holding the version of the service, upgrade sets them to the new version.
+	VersionFiles []VersionFile `json:"version_files" yaml:"version_files"`
+}
+
+// VersionFile is a file holding the version of a service.
+// The version is at JSON, TOML or Regex, the whole file is the version when none of them is set, e.g. VERSION.
+type VersionFile struct {
+	// File is relative to the repository, e.g. api/package.json.
+	File string `json:"file" yaml:"file" jsonschema:"required"`
+	// JSON is the path of the version, e.g. version.
+	JSON string `json:"json" yaml:"json"`
+	// TOML is the dotted key of the version, e.g. project.version or tool.poetry.version.
+	TOML string `json:"toml" yaml:"toml"`
+	// Regex replaces its first group, or the whole match without a group, e.g. const Version = "(.*)".
+	Regex string `json:"regex" yaml:"regex"`
+	// Value is the template of the version, {{.SemVer}} (e.g. 1.2.3) by default.
+	// The fields are Service, Version and SemVer, the commit is not known before the files are written.
+	Value string `json:"value" yaml:"value"`
+}
+
+// ReleaseCommit commits the version files before upgrade tags the commit.
+type ReleaseCommit struct {
+	// Enabled commits the version files by default, the --commit flag of upgrade takes precedence.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Message is the template of the commit message, the field Tags lists the new tags.
+	Message string `json:"message" yaml:"message"`
+}
+
+// Manifest is a value of a YAML file, selected either by Path or by Image.
+type Manifest struct {
+	// File is relative to the repository, e.g. deploy/api/values.yaml.
+	File string `json:"file" yaml:"file" jsonschema:"required"`
+	// Path is the YAML path of the value, e.g. image.tag or spec.template.spec.containers[0].image.
+	Path string `json:"path" yaml:"path"`
+	// Image sets the tag of the image in the images of a kustomization and in image keys, e.g. of docker-compose.
+	Image string `json:"image" yaml:"image"`
+	// Value is the template of the value of Path or of the tag of Image, the target_tag of images by default.
+	// The fields are Service, Version, SemVer, Commit and ShortCommit.
+	Value string `json:"value" yaml:"value"`
+}
+
+func (c *Config) ServiceNames() []domain.ServiceName {
+	names := make([]domain.ServiceName, 0, len(c.Services))
+	for _, service := range c.Services {
+		names = append(names, domain.ServiceName(service.Name))
+	}
+	return names
+}
+
+// Schema is the JSON Schema of the config file.
+func Schema() *schema.Schema {
+	return schema.Document(
+		schema.FromType(reflect.TypeOf(Config{})),
+		"msgtm config",
+	)
+}
+
+// Parse validates and decodes the config file.
+func Parse(fileName string, data []byte) (*Config, error) {
+	if err := schema.ValidateFile(Schema(), fileName, data); err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// RepoPath returns the path of Repo relative to the working directory,
+// or an empty string when Repo is not set.
+func (c *Config) RepoPath(fileName string) string {
+	if c.Repo == "" {
+		return ""
+	}
+	return relativeTo(fileName, c.Repo)
+}
+
+// Load reads the config file.
+// A missing file is not an error and results in an empty config.
+func Load(fileName string) (*Config, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return Parse(fileName, data)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"msgtm/pkg/schema"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	DefaultWorkspaceFileName  = "msgtm-workspace.yaml"
+	DefaultWorkspaceStateFile = "workspace-state.yaml"
+	DefaultStateFile          = "services-state.yaml"
+)
+
+// Workspace lists the repositories that are released together.
+type Workspace struct {
+	Repos []WorkspaceRepo `json:"repos" yaml:"repos" jsonschema:"required"`
+	// StateFile is the combined state file of all repositories, relative to the workspace file.
+	StateFile string `json:"state_file" yaml:"state_file"`
+}
+
+type WorkspaceRepo struct {
+	Name string `json:"name" yaml:"name" jsonschema:"required,pattern=^[a-zA-Z0-9._-]+$"`
+	// Path is the local path of the repository, relative to the workspace file.
+	Path string `json:"path" yaml:"path" jsonschema:"required"`
+	// StateFile is the state file of the repository, relative to its root.
+	StateFile string `json:"state_file" yaml:"state_file"`
+}
+
+// WorkspaceSchema is the JSON Schema of the workspace file.
+func WorkspaceSchema() *schema.Schema {
+	return schema.Document(
+		schema.FromType(reflect.TypeOf(Workspace{})),
+		"msgtm workspace",
+	)
+}
+
+// ParseWorkspace validates and decodes the workspace file.
+func ParseWorkspace(fileName string, data []byte) (*Workspace, error) {
+	if err := schema.ValidateFile(WorkspaceSchema(), fileName, data); err != nil {
+		return nil, err
+	}
+	workspace := &Workspace{}
+	if err := yaml.Unmarshal(data, workspace); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, repo := range workspace.Repos {
+		if seen[repo.Name] {
+			return nil, fmt.Errorf("%s: repo %s is listed more than once", fileName, repo.Name)
+		}
+		seen[repo.Name] = true
+	}
+	return workspace, nil
+}
+
+// LoadWorkspace reads the workspace file, unlike Load a missing file is an error.
+func LoadWorkspace(fileName string) (*Workspace, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+	return ParseWorkspace(fileName, data)
+}
+
+// RepoPath returns the path of repo relative to the working directory.
+func (w *Workspace) RepoPath(fileName string, repo WorkspaceRepo) string {
+	return relativeTo(fileName, repo.Path)
+}
+
+// StatePath returns the path of the combined state file relative to the working directory.
+func (w *Workspace) StatePath(fileName string) string {
+	if w.StateFile == "" {
+		return relativeTo(fileName, DefaultWorkspaceStateFile)
+	}
+	return relativeTo(fileName, w.StateFile)
+}
+
+// StateFileName returns the state file of the repository relative to its root.
+func (r WorkspaceRepo) StateFileName() string {
+	if r.StateFile == "" {
+		return DefaultStateFile
+	}
+	return r.StateFile
+}
+
+func relativeTo(fileName string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(fileName), path)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// Branch is a local branch or a remote-tracking branch.
+type Branch struct {
+	// Name is the name of the branch without the remote, e.g. release/1.0.
+	Name string
+	// Remote is the remote of a remote-tracking branch, empty for a local branch.
+	Remote string
+}
+
+// ParseBranchRef parses refs/heads/<name> and refs/remotes/<remote>/<name>.
+// The symbolic refs/remotes/<remote>/HEAD is not a branch.
+func ParseBranchRef(ref string) (Branch, bool) {
+	if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return Branch{Name: name}, true
+	}
+	rest, ok := strings.CutPrefix(ref, "refs/remotes/")
+	if !ok {
+		return Branch{}, false
+	}
+	remote, name, ok := strings.Cut(rest, "/")
+	if !ok || name == "HEAD" {
+		return Branch{}, false
+	}
+	return Branch{Name: name, Remote: remote}, true
+}
+
+// Ref is the full ref name of the branch.
+func (b Branch) Ref() string {
+	if b.Remote == "" {
+		return "refs/heads/" + b.Name
+	}
+	return "refs/remotes/" + b.Remote + "/" + b.Name
+}
+
+func (b Branch) String() string {
+	if b.Remote == "" {
+		return b.Name
+	}
+	return b.Remote + "/" + b.Name
+}
+
+// Matches reports whether the name of the branch matches one of the patterns, e.g. release/*.
+// Remote-tracking branches are matched without their remote.
+func (b Branch) Matches(patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, b.Name); ok {
+			return true
+		}
+	}
+	return false
+}
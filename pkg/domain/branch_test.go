@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"msgtm/pkg/domain"
+	"testing"
+)
+
+func TestParseBranchRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   domain.Branch
+		branch bool
+	}{
+		{"refs/heads/main", domain.Branch{Name: "main"}, true},
+		{"refs/heads/release/1.0", domain.Branch{Name: "release/1.0"}, true},
+		{"refs/remotes/origin/release/1.0", domain.Branch{Name: "release/1.0", Remote: "origin"}, true},
+		{"refs/remotes/origin/HEAD", domain.Branch{}, false},
+		{"refs/tags/api-v1.0.0", domain.Branch{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := domain.ParseBranchRef(tt.ref)
+		if ok != tt.branch || got != tt.want {
+			t.Errorf("ParseBranchRef(%s) = %v, %v, want %v, %v", tt.ref, got, ok, tt.want, tt.branch)
+		}
+		if ok && got.Ref() != tt.ref {
+			t.Errorf("Ref() = %s, want %s", got.Ref(), tt.ref)
+		}
+	}
+	patterns := []string{"main", "release/*"}
+	if !(domain.Branch{Name: "release/1.0", Remote: "origin"}).Matches(patterns) {
+		t.Errorf("origin/release/1.0 should match %v", patterns)
+	}
+	if (domain.Branch{Name: "feature/x"}).Matches(patterns) {
+		t.Errorf("feature/x should not match %v", patterns)
+	}
+}
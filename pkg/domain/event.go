@@ -0,0 +1,63 @@
+package domain
+
+// ReleaseAction is the command a release event comes from.
+type ReleaseAction string
+
+const (
+	ReleaseAdded    ReleaseAction = "add"
+	ReleaseUpgraded ReleaseAction = "upgrade"
+	ReleasePushed   ReleaseAction = "push"
+)
+
+// ReleaseEvent is a new version of a service, sent to the notification sinks.
+// It is encoded as the JSON of generic webhooks, so the field names are stable.
+type ReleaseEvent struct {
+	Action  ReleaseAction `json:"action"`
+	Service ServiceName   `json:"service"`
+	Tag     GitTag        `json:"tag"`
+	// Version is the new version, e.g. v1.2.3.
+	Version string `json:"version"`
+	// PreviousVersion is the highest version below Version, empty for the first version of the service.
+	PreviousVersion string   `json:"previous_version"`
+	Commit          CommitId `json:"commit"`
+	// Remote is the remote the tag was pushed to, empty when the tag is only local.
+	Remote string `json:"remote"`
+	// Changelog are the commits since PreviousVersion, newest first.
+	Changelog []Commit `json:"changelog"`
+	// Notes is the changelog rendered as Markdown.
+	Notes string `json:"notes"`
+}
+
+func NewReleaseEvent(action ReleaseAction, remote string, release *Release) *ReleaseEvent {
+	event := &ReleaseEvent{
+		Action:    action,
+		Service:   release.Tag.Service,
+		Tag:       release.Tag.ToGitTag(),
+		Version:   release.Tag.Version.String(),
+		Commit:    release.CommitId,
+		Remote:    remote,
+		Changelog: release.Changelog,
+		Notes:     release.Notes(),
+	}
+	if event.Changelog == nil {
+		event.Changelog = []Commit{}
+	}
+	if release.Previous != nil {
+		event.PreviousVersion = release.Previous.Version.String()
+	}
+	return event
+}
+
+// Name is the title of the event, e.g. api v1.2.3.
+func (e *ReleaseEvent) Name() string {
+	return string(e.Service) + " " + e.Version
+}
+
+// ShortCommit is the first 7 characters of Commit.
+func (e *ReleaseEvent) ShortCommit() string {
+	id := string(e.Commit)
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
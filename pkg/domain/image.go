@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Image is a tagged container image, e.g. registry.example.com/platform/api:v1.2.3.
+type Image struct {
+	// Repository is the image without the tag, including the registry.
+	Repository string
+	Tag        string
+}
+
+func (i Image) String() string {
+	return i.Repository + ":" + i.Tag
+}
+
+const (
+	DefaultImageSourceTag = "{{.Commit}}"
+	DefaultImageTargetTag = "{{.Version}}"
+)
+
+// ImageNaming names the images of services and their tags.
+// The tags are templates of ImageTagFields.
+type ImageNaming struct {
+	// Registry is the registry and namespace of the images, the image of a service is <Registry>/<service>.
+	Registry string
+	// Images are the images of services that are not named after the service.
+	Images map[ServiceName]string
+	// SourceTag is the tag an image is built with for a commit, DefaultImageSourceTag when empty.
+	SourceTag string
+	// TargetTag is the tag of the version of the service, DefaultImageTargetTag when empty.
+	TargetTag string
+}
+
+// ImageTagFields are the fields of the tag templates.
+type ImageTagFields struct {
+	Service string
+	// Version is the version of the service tag, e.g. v1.2.3.
+	Version string
+	// SemVer is the version without the v prefix, e.g. 1.2.3.
+	SemVer string
+	Commit string
+	// ShortCommit is the first 7 characters of Commit.
+	ShortCommit string
+}
+
+// Repository returns the image of the service without a tag.
+func (n *ImageNaming) Repository(service ServiceName) (string, error) {
+	if image, ok := n.Images[service]; ok {
+		return image, nil
+	}
+	if n.Registry == "" {
+		return "", fmt.Errorf("no image of %s, the registry of images is not set", service)
+	}
+	return strings.TrimSuffix(n.Registry, "/") + "/" + string(service), nil
+}
+
+// Source returns the image built for the commit of the service tag.
+func (n *ImageNaming) Source(tag *ServiceTagWithSemVer, commitId CommitId) (Image, error) {
+	return n.image(tag, commitId, n.SourceTag, DefaultImageSourceTag)
+}
+
+// Target returns the image of the version of the service tag.
+func (n *ImageNaming) Target(tag *ServiceTagWithSemVer, commitId CommitId) (Image, error) {
+	return n.image(tag, commitId, n.TargetTag, DefaultImageTargetTag)
+}
+
+func (n *ImageNaming) image(tag *ServiceTagWithSemVer, commitId CommitId, text string, defaultText string) (Image, error) {
+	repository, err := n.Repository(tag.Service)
+	if err != nil {
+		return Image{}, err
+	}
+	if text == "" {
+		text = defaultText
+	}
+	imageTag, err := RenderTag(text, tag, commitId)
+	if err != nil {
+		return Image{}, err
+	}
+	return Image{Repository: repository, Tag: imageTag}, nil
+}
+
+// RenderTag executes the template text with the ImageTagFields of the service tag at the commit.
+func RenderTag(text string, tag *ServiceTagWithSemVer, commitId CommitId) (string, error) {
+	t, err := template.New("tag").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid image tag %s: %w", text, err)
+	}
+	short := string(commitId)
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	b := &strings.Builder{}
+	err = t.Execute(b, ImageTagFields{
+		Service:     string(tag.Service),
+		Version:     tag.Version.String(),
+		SemVer:      strings.TrimPrefix(tag.Version.String(), "v"),
+		Commit:      string(commitId),
+		ShortCommit: short,
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid image tag %s: %w", text, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,49 @@
+package domain_test
+
+import (
+	"msgtm/pkg/domain"
+	"testing"
+)
+
+func TestImageNaming(t *testing.T) {
+	naming := &domain.ImageNaming{
+		Registry: "registry.example.com/platform/",
+		Images:   map[domain.ServiceName]string{"web": "ghcr.io/octo-org/frontend"},
+	}
+	commitId := domain.CommitId("0123456789abcdef")
+	api := domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 2, 3))
+
+	source, err := naming.Source(api, commitId)
+	if err != nil {
+		t.Fatalf("Source() error = %v, want nil", err)
+	}
+	if source.String() != "registry.example.com/platform/api:0123456789abcdef" {
+		t.Errorf("Source() = %s", source)
+	}
+	target, err := naming.Target(api, commitId)
+	if err != nil {
+		t.Fatalf("Target() error = %v, want nil", err)
+	}
+	if target.String() != "registry.example.com/platform/api:v1.2.3" {
+		t.Errorf("Target() = %s", target)
+	}
+
+	naming.SourceTag = "sha-{{.ShortCommit}}"
+	web := domain.NewServiceTagWithSemVer("web", domain.NewSemVer(0, 1, 0))
+	source, err = naming.Source(web, commitId)
+	if err != nil {
+		t.Fatalf("Source() error = %v, want nil", err)
+	}
+	if source.String() != "ghcr.io/octo-org/frontend:sha-0123456" {
+		t.Errorf("Source() = %s", source)
+	}
+
+	naming.TargetTag = "{{.Missing}}"
+	if _, err := naming.Target(web, commitId); err == nil {
+		t.Error("Target() with an unknown field error = nil, want an error")
+	}
+	naming = &domain.ImageNaming{}
+	if _, err := naming.Source(api, commitId); err == nil {
+		t.Error("Source() without a registry error = nil, want an error")
+	}
+}
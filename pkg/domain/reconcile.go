@@ -0,0 +1,47 @@
+package domain
+
+// TagConflict is a tag that points at different commits locally and on a remote.
+type TagConflict struct {
+	Tag    GitTag
+	Local  CommitId
+	Remote CommitId
+}
+
+type TagReconciliation struct {
+	// New are the tags only the remote has.
+	New []TagRef
+	// UpToDate are the tags pointing at the same commit locally and on the remote.
+	UpToDate  []TagRef
+	Conflicts []TagConflict
+}
+
+// ReconcileTags compares the remote tags with the local ones.
+// Tags only the local repository has are not part of the result.
+func ReconcileTags(local []TagRef, remote []TagRef) *TagReconciliation {
+	localCommits := map[GitTag]CommitId{}
+	for _, ref := range local {
+		localCommits[ref.Tag] = ref.CommitId
+	}
+	result := &TagReconciliation{
+		New:       []TagRef{},
+		UpToDate:  []TagRef{},
+		Conflicts: []TagConflict{},
+	}
+	for _, ref := range remote {
+		commitId, ok := localCommits[ref.Tag]
+		if !ok {
+			result.New = append(result.New, ref)
+			continue
+		}
+		if commitId == ref.CommitId {
+			result.UpToDate = append(result.UpToDate, ref)
+			continue
+		}
+		result.Conflicts = append(result.Conflicts, TagConflict{
+			Tag:    ref.Tag,
+			Local:  commitId,
+			Remote: ref.CommitId,
+		})
+	}
+	return result
+}
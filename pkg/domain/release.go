@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Commit is a commit of a changelog.
+type Commit struct {
+	Id      CommitId `json:"id"`
+	Subject string   `json:"subject"`
+}
+
+// Release is a service tag published on a hosting service.
+type Release struct {
+	Tag      *ServiceTagWithSemVer
+	CommitId CommitId
+	// Previous is the highest tag of the service below Tag, nil for the first release.
+	Previous *ServiceTagWithSemVer
+	// Changelog are the commits since Previous, newest first.
+	Changelog []Commit
+}
+
+// Name is the title of the release, e.g. api v1.2.3.
+func (r *Release) Name() string {
+	return fmt.Sprintf("%s %s", r.Tag.Service, r.Tag.Version.String())
+}
+
+// Prerelease reports whether the release is a pre-release,
+// versions below v1.0.0 are in initial development.
+func (r *Release) Prerelease() bool {
+	return r.Tag.Version.Major == 0
+}
+
+// Notes renders the changelog as Markdown.
+func (r *Release) Notes() string {
+	b := &strings.Builder{}
+	if r.Previous != nil {
+		fmt.Fprintf(b, "## Changes since %s\n\n", r.Previous.String())
+	} else {
+		b.WriteString("## Changes\n\n")
+	}
+	if len(r.Changelog) == 0 {
+		b.WriteString("No changes.\n")
+	}
+	for _, commit := range r.Changelog {
+		id := commit.Id.String()
+		if len(id) > 7 {
+			id = id[:7]
+		}
+		fmt.Fprintf(b, "- %s (%s)\n", commit.Subject, id)
+	}
+	return b.String()
+}
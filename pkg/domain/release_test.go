@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"msgtm/pkg/domain"
+	"testing"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	release := &domain.Release{
+		Tag:      domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 2, 0)),
+		Previous: domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 1, 0)),
+		Changelog: []domain.Commit{
+			{Id: "0123456789abcdef", Subject: "add endpoint"},
+			{Id: "fedcba9876543210", Subject: "fix typo"},
+		},
+	}
+	want := "## Changes since api-v1.1.0\n\n- add endpoint (0123456)\n- fix typo (fedcba9)\n"
+	if got := release.Notes(); got != want {
+		t.Errorf("Notes() = %q, want %q", got, want)
+	}
+	if got := release.Name(); got != "api v1.2.0" {
+		t.Errorf("Name() = %s, want api v1.2.0", got)
+	}
+	if release.Prerelease() {
+		t.Errorf("Prerelease() = true, want false for v1.2.0")
+	}
+
+	first := &domain.Release{Tag: domain.NewServiceTagWithSemVer("api", domain.NewSemVer(0, 1, 0))}
+	want = "## Changes\n\nNo changes.\n"
+	if got := first.Notes(); got != want {
+		t.Errorf("Notes() = %q, want %q", got, want)
+	}
+	if !first.Prerelease() {
+		t.Errorf("Prerelease() = false, want true for v0.1.0")
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "strings"
+
+// ServiceVersion is the version of a service at a commit, handed to CI jobs, builds and scripts.
+type ServiceVersion struct {
+	Service ServiceName `json:"service" yaml:"service"`
+	// Version is the version without the v prefix, e.g. 1.2.3.
+	Version string   `json:"version" yaml:"version"`
+	Tag     GitTag   `json:"tag" yaml:"tag"`
+	Commit  CommitId `json:"commit" yaml:"commit"`
+}
+
+func NewServiceVersion(tag *ServiceTagWithSemVer, commitId CommitId) ServiceVersion {
+	return ServiceVersion{
+		Service: tag.Service,
+		Version: strings.TrimPrefix(tag.Version.String(), "v"),
+		Tag:     tag.ToGitTag(),
+		Commit:  commitId,
+	}
+}
+
+// Variable is an environment variable of a service version.
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// VariableName is the environment variable of a property of a service, e.g. USER_API_VERSION.
+func VariableName(service ServiceName, property string) string {
+	name := strings.ToUpper(strings.ReplaceAll(string(service), "-", "_"))
+	return name + "_" + property
+}
+
+// Variables are the version, the tag and the commit, e.g. API_VERSION=1.2.3, API_TAG=api-v1.2.3 and API_COMMIT.
+func (v ServiceVersion) Variables() []Variable {
+	return []Variable{
+		{Name: VariableName(v.Service, "VERSION"), Value: v.Version},
+		{Name: VariableName(v.Service, "TAG"), Value: string(v.Tag)},
+		{Name: VariableName(v.Service, "COMMIT"), Value: string(v.Commit)},
+	}
+}
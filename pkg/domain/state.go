@@ -108,8 +108,7 @@ func (s *WritedState) Write(writer io.Writer, format WriteFormat) error {
 }
 
 func FromReader(reader io.Reader, format WriteFormat) (*WritedState, error) {
-	b := []byte{}
-	_, err := reader.Read(b)
+	b, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, err
 	}
@@ -133,20 +132,24 @@ func FromReader(reader io.Reader, format WriteFormat) (*WritedState, error) {
 }
 
 type marshaledState struct {
-	Services []struct {
-		Name   string                    `json:"name" yaml:"name"`
-		Latest *marshaledServiceTagState `json:"latest" yaml:"latest"`
-		Prev   *marshaledServiceTagState `json:"prev" yaml:"prev"`
-	} `json:"services" yaml:"services"`
+	Services []marshaledService `json:"services" yaml:"services" jsonschema:"required"`
+}
+
+type marshaledService struct {
+	Name   string                    `json:"name" yaml:"name" jsonschema:"required,pattern=^[a-zA-Z0-9-]+$"`
+	Latest *marshaledServiceTagState `json:"latest" yaml:"latest"`
+	Prev   *marshaledServiceTagState `json:"prev" yaml:"prev"`
 }
 
 type marshaledServiceTagState struct {
-	Tag struct {
-		Version string `json:"version" yaml:"version"`
-	} `json:"tag" yaml:"tag"`
-	CommitId      string  `json:"commitId" yaml:"commitId"`
-	Description   *string `json:"description" yaml:"description"`
-	CommitComment *string `json:"commitComment" yaml:"commitComment"`
+	Tag           marshaledTag `json:"tag" yaml:"tag" jsonschema:"required"`
+	CommitId      string       `json:"commitId" yaml:"commitId" jsonschema:"required"`
+	Description   *string      `json:"description" yaml:"description"`
+	CommitComment *string      `json:"commitComment" yaml:"commitComment"`
+}
+
+type marshaledTag struct {
+	Version string `json:"version" yaml:"version" jsonschema:"required,pattern=^v?[0-9]+\\.[0-9]+\\.[0-9]+$"`
 }
 
 func (s *WritedState) MarshalJSON() ([]byte, error) {
@@ -177,13 +180,13 @@ func (s *WritedState) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 func (s *WritedState) fromMarshaled(m marshaledState) error {
 	states := make([]*ServiceTagState, 0, len(m.Services))
-	for _, service := range m.Services {
+	for i, service := range m.Services {
 		name := ServiceName(service.Name)
 		state := InitServiceTagState(&name)
 		if service.Latest != nil {
 			version, err := FromStr(service.Latest.Tag.Version)
 			if err != nil {
-				return err
+				return fmt.Errorf("services[%d].latest.tag.version: %w", i, err)
 			}
 			serviceTag := NewServiceTagWithSemVer(name, version)
 			commitId := CommitId(service.Latest.CommitId)
@@ -207,7 +210,7 @@ func (s *WritedState) fromMarshaled(m marshaledState) error {
 		if service.Prev != nil {
 			version, err := FromStr(service.Prev.Tag.Version)
 			if err != nil {
-				return err
+				return fmt.Errorf("services[%d].prev.tag.version: %w", i, err)
 			}
 			serviceTag := NewServiceTagWithSemVer(name, version)
 			commitId := CommitId(service.Prev.CommitId)
@@ -233,20 +236,12 @@ func (s *WritedState) fromMarshaled(m marshaledState) error {
 }
 
 func (s *WritedState) toMarshaled() marshaledState {
-	services := make([]struct {
-		Name   string                    `json:"name" yaml:"name"`
-		Latest *marshaledServiceTagState `json:"latest" yaml:"latest"`
-		Prev   *marshaledServiceTagState `json:"prev" yaml:"prev"`
-	}, 0, len(s.ServiceTagStates))
+	services := make([]marshaledService, 0, len(s.ServiceTagStates))
 	m := marshaledState{
 		Services: services,
 	}
 	for _, state := range s.ServiceTagStates {
-		service := struct {
-			Name   string                    `json:"name" yaml:"name"`
-			Latest *marshaledServiceTagState `json:"latest" yaml:"latest"`
-			Prev   *marshaledServiceTagState `json:"prev" yaml:"prev"`
-		}{
+		service := marshaledService{
 			Name: state.ServiceName.String(),
 		}
 
@@ -261,9 +256,7 @@ func (s *WritedState) toMarshaled() marshaledState {
 			}
 
 			service.Latest = &marshaledServiceTagState{
-				Tag: struct {
-					Version string `json:"version" yaml:"version"`
-				}{
+				Tag: marshaledTag{
 					Version: state.Latest.Tag.Version.String(),
 				},
 				CommitId:      state.Latest.CommitId.String(),
@@ -282,9 +275,7 @@ func (s *WritedState) toMarshaled() marshaledState {
 			}
 
 			service.Prev = &marshaledServiceTagState{
-				Tag: struct {
-					Version string `json:"version" yaml:"version"`
-				}{
+				Tag: marshaledTag{
 					Version: state.Prev.Tag.Version.String(),
 				},
 				CommitId:      state.Prev.CommitId.String(),
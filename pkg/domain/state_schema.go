@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"msgtm/pkg/schema"
+	"reflect"
+)
+
+// StateSchema is the JSON Schema of the state file written by WritedState.Write.
+func StateSchema() *schema.Schema {
+	return schema.Document(
+		schema.FromType(reflect.TypeOf(marshaledState{})),
+		"msgtm services state",
+	)
+}
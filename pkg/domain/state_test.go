@@ -367,3 +367,23 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalInvalidVersion(t *testing.T) {
+	data := []byte(`
+services:
+    - name: test
+      latest:
+        tag:
+            version: v1.0.0
+        commitId: commit1
+      prev:
+        tag:
+            version: 1.2
+        commitId: commit0`)
+	var got domain.WritedState
+	err := yaml.Unmarshal(data, &got)
+	want := "services[0].prev.tag.version: invalid semver string: 1.2"
+	if err == nil || err.Error() != want {
+		t.Errorf("got: %v, want: %s", err, want)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 type GitTag string
@@ -256,3 +257,37 @@ func SortsServiceTags(tags *[]*ServiceTagWithSemVer) map[ServiceName][]*ServiceT
 	}
 	return sorted
 }
+
+// ValidateRefName checks that the tag can be created as refs/tags/<tag>,
+// following the rules of git check-ref-format.
+func (g GitTag) ValidateRefName() error {
+	name := g.String()
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid tag name %q: %s", name, reason)
+	}
+	if name == "" || name == "@" {
+		return invalid("empty or @")
+	}
+	if strings.HasPrefix(name, "-") {
+		return invalid("starts with -")
+	}
+	if strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") {
+		return invalid("ends with / or .")
+	}
+	for _, sequence := range []string{"..", "//", "@{"} {
+		if strings.Contains(name, sequence) {
+			return invalid(fmt.Sprintf("contains %s", sequence))
+		}
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return invalid(fmt.Sprintf("contains %q", r))
+		}
+	}
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return invalid("a component starts with . or ends with .lock")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+type TagType string
+
+const (
+	LightweightTag TagType = "lightweight"
+	AnnotatedTag   TagType = "annotated"
+)
+
+// TagRef is a tag with the commit it points at.
+type TagRef struct {
+	Tag      GitTag
+	CommitId CommitId
+	Type     TagType
+	// TaggerDate is the date of the tag object for annotated tags,
+	// and the committer date of the commit for lightweight tags.
+	TaggerDate time.Time
+	// Subject is the first line of the tag message for annotated tags,
+	// and of the commit message for lightweight tags.
+	Subject string
+}
+
+// IsServiceTagOf reports whether the ref is a service tag of one of services.
+// Every service tag matches when services is empty.
+func (r *TagRef) IsServiceTagOf(services []ServiceName) bool {
+	serviceTag, err := r.Tag.ToServiceTag()
+	if err != nil {
+		return false
+	}
+	if len(services) == 0 {
+		return true
+	}
+	for _, service := range services {
+		if serviceTag.Service == service {
+			return true
+		}
+	}
+	return false
+}
@@ -481,3 +481,18 @@ func cmpArrayContent[T any](a, b []T) bool {
 	}
 	return true
 }
+
+func TestValidateRefName(t *testing.T) {
+	valid := []domain.GitTag{"service-a-v1.0.0", "group/service-v0.1.2"}
+	for _, tag := range valid {
+		if err := tag.ValidateRefName(); err != nil {
+			t.Errorf("ValidateRefName(%q) = %v, want nil", tag, err)
+		}
+	}
+	invalid := []domain.GitTag{"", "@", "-a-v1.0.0", "a b-v1.0.0", "a..b", "a~1", "a^", "a:b", "a.lock", "a/.b", "a/", "a.", "a@{1}", "a\\b"}
+	for _, tag := range invalid {
+		if err := tag.ValidateRefName(); err == nil {
+			t.Errorf("ValidateRefName(%q) = nil, want error", tag)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WorkspaceState combines the state files of the repositories of a workspace.
+type WorkspaceState struct {
+	Repos []*RepoState `json:"repos" yaml:"repos"`
+}
+
+type RepoState struct {
+	Name  string       `json:"name" yaml:"name"`
+	Path  string       `json:"path" yaml:"path"`
+	State *WritedState `json:"state" yaml:"state"`
+}
+
+func (s *WorkspaceState) Write(writer io.Writer) error {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(b)
+	if err != nil {
+		return fmt.Errorf("failed to write workspace state: %w", err)
+	}
+	return nil
+}
+
+func WorkspaceStateFromReader(reader io.Reader) (*WorkspaceState, error) {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	state := &WorkspaceState{}
+	if err := yaml.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Repo returns the state of the repository named name, nil when it is not recorded.
+func (s *WorkspaceState) Repo(name string) *RepoState {
+	for _, repo := range s.Repos {
+		if repo.Name == name {
+			return repo
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package executor
+
+import (
+	"msgtm/pkg/usecase"
+	"strings"
+)
+
+type GitAncestorChecker struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitAncestorChecker) Execute(query usecase.IsAncestorQuery) (bool, error) {
+	commitId, err := gitRevParseCommit(g.GitCommandExecutor, query.CommitId.String())
+	if err != nil {
+		return false, err
+	}
+	if _, err := gitRevParseCommit(g.GitCommandExecutor, query.Revision); err != nil {
+		return false, nil
+	}
+	// nothing is left when the commit is reachable from the revision
+	output, err := g.GitCommandExecutor("rev-list", "-n", "1", commitId, "--not", query.Revision, "--")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) == "", nil
+}
@@ -0,0 +1,39 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+func TestGitAncestorChecker(t *testing.T) {
+	r := newTestRepository(t)
+	first := domain.CommitId(r.commit("first commit"))
+	r.git("switch", "--quiet", "-c", "feature")
+	feature := domain.CommitId(r.commit("feature commit"))
+
+	checker := &executor.GitAncestorChecker{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	tests := []struct {
+		commitId domain.CommitId
+		revision string
+		want     bool
+	}{
+		{first, "main", true},
+		{first, "feature", true},
+		{feature, "main", false},
+		{feature, "feature", true},
+		{first, "release/1.0", false},
+	}
+	for _, tt := range tests {
+		got, err := checker.Execute(usecase.IsAncestorQuery{CommitId: &tt.commitId, Revision: tt.revision})
+		if err != nil {
+			t.Fatalf("Execute(%s, %s) error = %v, want nil", tt.commitId, tt.revision, err)
+		}
+		if got != tt.want {
+			t.Errorf("Execute(%s, %s) = %v, want %v", tt.commitId, tt.revision, got, tt.want)
+		}
+	}
+}
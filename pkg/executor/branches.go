@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+)
+
+type GitBranchList struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitBranchList) Execute(query usecase.ListBranchesQuery) (*[]domain.Branch, error) {
+	output, err := g.GitCommandExecutor("for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	branches := []domain.Branch{}
+	for _, ref := range strings.Split(output, "\n") {
+		if branch, ok := domain.ParseBranchRef(strings.TrimSpace(ref)); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return &branches, nil
+}
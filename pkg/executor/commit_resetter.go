@@ -0,0 +1,20 @@
+package executor
+
+import "msgtm/pkg/usecase"
+
+// GitCommitResetter moves HEAD back with a soft reset and unstages only the files of the command.
+type GitCommitResetter struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitCommitResetter) Execute(cmd usecase.ResetCommitCommand) error {
+	_, err := g.GitCommandExecutor("reset", "--soft", string(*cmd.CommitId))
+	if err != nil {
+		return err
+	}
+	if len(cmd.Files) == 0 {
+		return nil
+	}
+	_, err = g.GitCommandExecutor(append([]string{"reset", "--quiet", string(*cmd.CommitId), "--"}, cmd.Files...)...)
+	return err
+}
@@ -11,7 +11,11 @@ type CommitTagGetter struct {
 
 func (c *CommitTagGetter) Execute(query usecase.GetCommitTagQuery) (*[]domain.GitTag, error) {
 	result := []domain.GitTag{}
-	tags, err := gitShowCommitTags(c.GitCommandExecutor, query.CommitId.String())
+	commitId, err := gitRevParseCommit(c.GitCommandExecutor, query.CommitId.String())
+	if err != nil {
+		return nil, err
+	}
+	tags, err := gitTagPointsAt(c.GitCommandExecutor, commitId)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,89 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCommitTagGetter(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *testRepository) string
+		want  []domain.GitTag
+	}{
+		{
+			name: "commit without tags",
+			setup: func(r *testRepository) string {
+				return r.commit("init")
+			},
+			want: []domain.GitTag{},
+		},
+		{
+			name: "lightweight and annotated tags",
+			setup: func(r *testRepository) string {
+				commitId := r.commit("init")
+				r.git("tag", "service-a-v1.0.0")
+				r.git("tag", "-a", "service-b-v1.0.0", "-m", "release service-b")
+				r.commit("next")
+				r.git("tag", "service-a-v1.0.1")
+				return commitId
+			},
+			want: []domain.GitTag{"service-a-v1.0.0", "service-b-v1.0.0"},
+		},
+		{
+			name: "decorations of branches and custom log settings are ignored",
+			setup: func(r *testRepository) string {
+				r.git("config", "log.decorate", "full")
+				r.git("config", "format.pretty", "oneline")
+				commitId := r.commit("init")
+				r.git("branch", "feature/a,b")
+				r.git("tag", "service-a-v1.0.0")
+				return commitId
+			},
+			want: []domain.GitTag{"service-a-v1.0.0"},
+		},
+		{
+			name: "HEAD",
+			setup: func(r *testRepository) string {
+				r.commit("init")
+				r.git("tag", "-a", "service-a-v1.0.0", "-m", "release service-a")
+				return "HEAD"
+			},
+			want: []domain.GitTag{"service-a-v1.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepository(t)
+			commitId := domain.CommitId(tt.setup(r))
+			getter := &executor.CommitTagGetter{
+				GitCommandExecutor: executor.GitShellCommandExecutor(),
+			}
+			got, err := getter.Execute(usecase.GetCommitTagQuery{CommitId: &commitId})
+			if err != nil {
+				t.Fatalf("Execute() error = %v, want nil", err)
+			}
+			sort.Slice(*got, func(i, j int) bool { return (*got)[i] < (*got)[j] })
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitTagGetterUnknownCommit(t *testing.T) {
+	r := newTestRepository(t)
+	r.commit("init")
+	commitId := domain.CommitId("0123456789abcdef0123456789abcdef01234567")
+	getter := &executor.CommitTagGetter{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	_, err := getter.Execute(usecase.GetCommitTagQuery{CommitId: &commitId})
+	if err == nil {
+		t.Errorf("Execute() error = nil, want error")
+	}
+}
@@ -0,0 +1,31 @@
+package executor
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+)
+
+type GitCommitList struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitCommitList) Execute(query usecase.ListCommitsQuery) (*[]domain.Commit, error) {
+	args := []string{"log", "--format=%H%x00%s", query.To.String()}
+	if query.From != nil {
+		args = append(args, "--not", query.From.String())
+	}
+	output, err := g.GitCommandExecutor(append(args, "--")...)
+	if err != nil {
+		return nil, err
+	}
+	commits := []domain.Commit{}
+	for _, line := range strings.Split(output, "\n") {
+		id, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, domain.Commit{Id: domain.CommitId(id), Subject: subject})
+	}
+	return &commits, nil
+}
@@ -0,0 +1,37 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestGitCommitList(t *testing.T) {
+	r := newTestRepository(t)
+	first := domain.CommitId(r.commit("first commit"))
+	second := domain.CommitId(r.commit("second commit"))
+	third := domain.CommitId(r.commit("third commit\n\nbody"))
+
+	list := &executor.GitCommitList{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	commits, err := list.Execute(usecase.ListCommitsQuery{From: &first, To: &third})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want := []domain.Commit{{Id: third, Subject: "third commit"}, {Id: second, Subject: "second commit"}}
+	if !reflect.DeepEqual(*commits, want) {
+		t.Errorf("Execute() = %v, want %v", *commits, want)
+	}
+
+	commits, err = list.Execute(usecase.ListCommitsQuery{To: &second})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want = []domain.Commit{{Id: second, Subject: "second commit"}, {Id: first, Subject: "first commit"}}
+	if !reflect.DeepEqual(*commits, want) {
+		t.Errorf("Execute() without From = %v, want %v", *commits, want)
+	}
+}
@@ -0,0 +1,17 @@
+package executor
+
+import "msgtm/pkg/usecase"
+
+// GitCommitter commits only the files of the command, other staged changes are left staged.
+type GitCommitter struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitCommitter) Execute(cmd usecase.CreateCommitCommand) error {
+	_, err := g.GitCommandExecutor(append([]string{"add", "--"}, cmd.Files...)...)
+	if err != nil {
+		return err
+	}
+	_, err = g.GitCommandExecutor(append([]string{"commit", "--quiet", "-m", cmd.Message, "--"}, cmd.Files...)...)
+	return err
+}
@@ -0,0 +1,70 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"os"
+	"testing"
+)
+
+func TestGitCommitter(t *testing.T) {
+	r := newTestRepository(t)
+	first := r.commit("first commit")
+	if err := os.WriteFile("VERSION", []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("other.txt", []byte("staged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r.git("add", "other.txt")
+
+	committer := &executor.GitCommitter{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	err := committer.Execute(usecase.CreateCommitCommand{Message: "Release api-v1.0.0", Files: []string{"VERSION"}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if parent := r.git("rev-parse", "HEAD^"); parent != first {
+		t.Errorf("parent of the release commit = %s, want %s", parent, first)
+	}
+	if files := r.git("show", "--name-only", "--format=%s", "HEAD"); files != "Release api-v1.0.0\n\nVERSION" {
+		t.Errorf("release commit = %q, want only VERSION", files)
+	}
+	if staged := r.git("diff", "--cached", "--name-only"); staged != "other.txt" {
+		t.Errorf("staged = %q, want other.txt left staged", staged)
+	}
+}
+
+func TestGitCommitResetter(t *testing.T) {
+	r := newTestRepository(t)
+	first := r.commit("first commit")
+	if err := os.WriteFile("VERSION", []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("other.txt", []byte("staged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r.git("add", "other.txt")
+	gitExecutor := executor.GitShellCommandExecutor()
+	err := (&executor.GitCommitter{GitCommandExecutor: gitExecutor}).Execute(usecase.CreateCommitCommand{Message: "Release api-v1.0.0", Files: []string{"VERSION"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commitId := domain.CommitId(first)
+	err = (&executor.GitCommitResetter{GitCommandExecutor: gitExecutor}).Execute(usecase.ResetCommitCommand{CommitId: &commitId, Files: []string{"VERSION"}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if head := r.git("rev-parse", "HEAD"); head != first {
+		t.Errorf("HEAD = %s, want %s", head, first)
+	}
+	if staged := r.git("diff", "--cached", "--name-only"); staged != "other.txt" {
+		t.Errorf("staged = %q, want only other.txt left staged", staged)
+	}
+	if data, err := os.ReadFile("VERSION"); err != nil || string(data) != "1.0.0\n" {
+		t.Errorf("VERSION = %q, %v, want the working tree kept", data, err)
+	}
+}
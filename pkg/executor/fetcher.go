@@ -0,0 +1,19 @@
+package executor
+
+import "msgtm/pkg/usecase"
+
+type GitTagFetcher struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitTagFetcher) Execute(cmd usecase.FetchTagsCommand) error {
+	tagStrs := []string{}
+	for _, tag := range *cmd.Tags {
+		tagStrs = append(tagStrs, tag.String())
+	}
+	_, err := gitFetchTags(g.GitCommandExecutor, cmd.RemoteAddr.String(), cmd.Force, tagStrs...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package executor
+
+import (
+	"errors"
+	"io/fs"
+	"msgtm/pkg/usecase"
+	"os"
+)
+
+// FileWriter replaces the content of files, keeping the permissions of existing files.
+type FileWriter struct{}
+
+func (f *FileWriter) Execute(cmd usecase.WriteFileCommand) error {
+	mode := fs.FileMode(0o644)
+	info, err := os.Stat(cmd.Name)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.WriteFile(cmd.Name, cmd.Data, mode)
+}
@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"bytes"
+	"fmt"
 	"log/slog"
 	"msgtm/pkg/domain"
 	"os/exec"
@@ -9,14 +11,49 @@ import (
 
 type GitCommandExecutor func(args ...string) (string, error)
 
+// GitShellCommandExecutor runs the git binary and returns its standard output,
+// so that warnings written to standard error never end up in parsed output.
+// On failure the standard error is returned as the output and included in the error.
 func GitShellCommandExecutor() GitCommandExecutor {
+	return GitShellCommandExecutorIn("")
+}
+
+// GitShellCommandExecutorIn is GitShellCommandExecutor running git in dir,
+// or in the working directory when dir is empty.
+func GitShellCommandExecutorIn(dir string) GitCommandExecutor {
 	return func(args ...string) (string, error) {
 		cmd := exec.Command("git", args...)
-		output, err := cmd.CombinedOutput()
-		return string(output), err
+		cmd.Dir = dir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		if err != nil {
+			message := strings.TrimSpace(stderr.String())
+			return stderr.String(), fmt.Errorf("git %s: %w: %s", args[0], err, message)
+		}
+		return stdout.String(), nil
 	}
 }
 
+// RepositoryRoot returns the top level directory of the working tree git runs in.
+func RepositoryRoot(executor GitCommandExecutor) (string, error) {
+	output, err := executor("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// HooksDir returns the absolute path of the hooks directory, honoring core.hooksPath.
+func HooksDir(executor GitCommandExecutor) (string, error) {
+	output, err := executor("rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
 type outputTransformer func(string) string
 
 func LogDecorateToExecutor(gitCmd GitCommandExecutor, logger *slog.Logger, outputTransformer outputTransformer) GitCommandExecutor {
@@ -71,35 +108,72 @@ func gitTagRemoteDelete(executor GitCommandExecutor, remote string, tags []strin
 	return executor(cmdArgs...)
 }
 
-func gitShowCommitTags(executor GitCommandExecutor, commitId string) ([]string, error) {
-	output, err := gitShowCommit(executor, commitId)
+// gitTagPointsAt lists the tags of the commit, annotated tags are peeled to their commit.
+func gitTagPointsAt(executor GitCommandExecutor, commitId string) ([]string, error) {
+	output, err := executor("tag", "--points-at", commitId)
 	if err != nil {
 		return nil, err
 	}
-	commitLine := strings.Split(output, "\n")[0]
-	// tag: service1-v1.1.1, tags: service2-v1.1.1
-	tagsStr := strings.Split(commitLine, "(")[1]
-	// remove ")"
-	tagsStr = tagsStr[:len(tagsStr)-1]
 	result := []string{}
-	for _, tagStr := range strings.Split(tagsStr, ", ") {
-		if !strings.HasPrefix(tagStr, "tag: ") {
+	for _, tag := range strings.Split(output, "\n") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
 			continue
 		}
-		result = append(result, strings.Split(tagStr, "tag: ")[1])
+		result = append(result, tag)
 	}
 	return result, nil
 }
+
+// gitRevParseCommit resolves a revision such as HEAD to the id of its commit.
+func gitRevParseCommit(executor GitCommandExecutor, revision string) (string, error) {
+	output, err := executor("rev-parse", "--verify", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %s: %w", revision, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
 func gitRevList(executor GitCommandExecutor, tag string) (string, error) {
 	return executor("rev-list", "-n", "1", tag)
 }
 
-func gitShowCommit(executor GitCommandExecutor, commitId string) (string, error) {
-	return executor("show", commitId, "--decorate")
+func gitPushTags(executor GitCommandExecutor, remote string, atomic bool, tags ...string) (string, error) {
+	args := []string{"push"}
+	if atomic {
+		args = append(args, "--atomic")
+	}
+	args = append(args, remote)
+	args = append(args, tags...)
+	return executor(args...)
 }
 
-func gitPushTags(executor GitCommandExecutor, remote string, tags ...string) (string, error) {
-	args := []string{"push", remote}
-	args = append(args, tags...)
+// fields of a tag ref separated by NUL, see gitForEachTagRef
+const tagRefFormat = "%(refname:strip=2)%00%(objecttype)%00%(objectname)%00%(*objectname)%00%(creatordate:unix)%00%(contents:subject)"
+
+func gitForEachTagRef(executor GitCommandExecutor, patterns ...string) (string, error) {
+	args := []string{"for-each-ref", "--format=" + tagRefFormat}
+	if len(patterns) == 0 {
+		patterns = []string{"refs/tags"}
+	}
+	args = append(args, patterns...)
+	return executor(args...)
+}
+
+func gitLsRemoteTags(executor GitCommandExecutor, remote string, patterns ...string) (string, error) {
+	args := []string{"ls-remote", "--tags", remote}
+	args = append(args, patterns...)
+	return executor(args...)
+}
+
+func gitFetchTags(executor GitCommandExecutor, remote string, force bool, tags ...string) (string, error) {
+	args := []string{"fetch", "--no-tags", remote}
+	for _, tag := range tags {
+		refSpec := fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag)
+		if force {
+			refSpec = "+" + refSpec
+		}
+		args = append(args, refSpec)
+	}
 	return executor(args...)
 }
@@ -0,0 +1,34 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type AncestorChecker struct {
+	Repository *git.Repository
+}
+
+func (a *AncestorChecker) Execute(query usecase.IsAncestorQuery) (bool, error) {
+	hash, err := a.Repository.ResolveRevision(plumbing.Revision(query.CommitId.String()))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", query.CommitId.String(), err)
+	}
+	revision, err := a.Repository.ResolveRevision(plumbing.Revision(query.Revision))
+	if err != nil {
+		return false, nil
+	}
+	commit, err := a.Repository.CommitObject(*hash)
+	if err != nil {
+		return false, err
+	}
+	other, err := a.Repository.CommitObject(*revision)
+	if err != nil {
+		return false, err
+	}
+	// a commit is an ancestor of itself
+	return commit.IsAncestor(other)
+}
@@ -0,0 +1,34 @@
+package gogit
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type BranchList struct {
+	Repository *git.Repository
+}
+
+func (b *BranchList) Execute(query usecase.ListBranchesQuery) (*[]domain.Branch, error) {
+	refs, err := b.Repository.References()
+	if err != nil {
+		return nil, err
+	}
+	branches := []domain.Branch{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if branch, ok := domain.ParseBranchRef(ref.Name().String()); ok {
+			branches = append(branches, branch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &branches, nil
+}
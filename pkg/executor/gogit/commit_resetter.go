@@ -0,0 +1,25 @@
+package gogit
+
+import (
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// CommitResetter moves HEAD back with a mixed reset, go-git can not reset only some files of the index.
+// Committer refuses to commit with other staged changes, so only the files of the command are unstaged.
+type CommitResetter struct {
+	Repository *git.Repository
+}
+
+func (r *CommitResetter) Execute(cmd usecase.ResetCommitCommand) error {
+	worktree, err := r.Repository.Worktree()
+	if err != nil {
+		return err
+	}
+	return worktree.Reset(&git.ResetOptions{
+		Commit: plumbing.NewHash(string(*cmd.CommitId)),
+		Mode:   git.MixedReset,
+	})
+}
@@ -0,0 +1,40 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type CommitTagGetter struct {
+	Repository *git.Repository
+}
+
+func (c *CommitTagGetter) Execute(query usecase.GetCommitTagQuery) (*[]domain.GitTag, error) {
+	commit, err := c.Repository.ResolveRevision(plumbing.Revision(query.CommitId.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", query.CommitId.String(), err)
+	}
+	refs, err := c.Repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.GitTag{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		target, err := peel(c.Repository, ref)
+		if err != nil {
+			return err
+		}
+		if target == *commit {
+			result = append(result, domain.GitTag(ref.Name().Short()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
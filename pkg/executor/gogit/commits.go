@@ -0,0 +1,59 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type CommitList struct {
+	Repository *git.Repository
+}
+
+func (c *CommitList) Execute(query usecase.ListCommitsQuery) (*[]domain.Commit, error) {
+	to, err := c.Repository.ResolveRevision(plumbing.Revision(query.To.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", query.To.String(), err)
+	}
+	// the commits reachable from From, including those behind merges
+	excluded := map[plumbing.Hash]bool{}
+	if query.From != nil {
+		from, err := c.Repository.ResolveRevision(plumbing.Revision(query.From.String()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", query.From.String(), err)
+		}
+		iter, err := c.Repository.Log(&git.LogOptions{From: *from})
+		if err != nil {
+			return nil, err
+		}
+		err = iter.ForEach(func(commit *object.Commit) error {
+			excluded[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	iter, err := c.Repository.Log(&git.LogOptions{From: *to})
+	if err != nil {
+		return nil, err
+	}
+	commits := []domain.Commit{}
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if excluded[commit.Hash] {
+			return nil
+		}
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		commits = append(commits, domain.Commit{Id: domain.CommitId(commit.Hash.String()), Subject: subject})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &commits, nil
+}
@@ -0,0 +1,65 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/usecase"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Committer commits the files of the command on HEAD.
+// go-git commits the whole index, so unlike executor.GitCommitter, which leaves other staged changes staged,
+// it refuses to commit when changes of other files are staged.
+type Committer struct {
+	// Author of the commits, the user of the git config is used when nil.
+	Author     *object.Signature
+	Repository *git.Repository
+}
+
+func (c *Committer) Execute(cmd usecase.CreateCommitCommand) error {
+	worktree, err := c.Repository.Worktree()
+	if err != nil {
+		return err
+	}
+	root, err := RepositoryRoot(c.Repository)
+	if err != nil {
+		return err
+	}
+	paths := map[string]bool{}
+	for _, file := range cmd.Files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		path, err := filepath.Rel(root, abs)
+		if err != nil {
+			return err
+		}
+		paths[filepath.ToSlash(path)] = true
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	staged := []string{}
+	for path, fileStatus := range status {
+		if !paths[path] && fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			staged = append(staged, path)
+		}
+	}
+	if len(staged) > 0 {
+		sort.Strings(staged)
+		return fmt.Errorf("changes of other files are staged, commit or unstage them first: %s", strings.Join(staged, ", "))
+	}
+	for path := range paths {
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to add %s: %w", path, err)
+		}
+	}
+	_, err = worktree.Commit(cmd.Message, &git.CommitOptions{Author: c.Author})
+	return err
+}
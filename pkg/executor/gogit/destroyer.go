@@ -0,0 +1,38 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+type LocalServiceTagsDestroyer struct {
+	Repository *git.Repository
+}
+
+func (l *LocalServiceTagsDestroyer) Execute(cmd usecase.DestroyServiceTagsCommand) error {
+	for _, tag := range *cmd.Tags {
+		err := l.Repository.DeleteTag(tag.String())
+		if err != nil {
+			return fmt.Errorf("failed to delete tag %s: %w", tag.String(), err)
+		}
+	}
+	return nil
+}
+
+type RemoteServiceTagsDestroyer struct {
+	Remote     *domain.RemoteAddr
+	Auth       transport.AuthMethod
+	Repository *git.Repository
+}
+
+func (r *RemoteServiceTagsDestroyer) Execute(cmd usecase.DestroyServiceTagsCommand) error {
+	refSpecs := []string{}
+	for _, tag := range *cmd.Tags {
+		refSpecs = append(refSpecs, ":"+tagRefName(tag.String()).String())
+	}
+	return pushRefSpecs(r.Repository, r.Remote.String(), r.Auth, false, refSpecs)
+}
@@ -0,0 +1,41 @@
+package gogit
+
+import (
+	"errors"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+type TagFetcher struct {
+	Auth       transport.AuthMethod
+	Repository *git.Repository
+}
+
+func (t *TagFetcher) Execute(cmd usecase.FetchTagsCommand) error {
+	remote, err := remoteOf(t.Repository, cmd.RemoteAddr.String())
+	if err != nil {
+		return err
+	}
+	options := &git.FetchOptions{
+		RemoteName: remote.Config().Name,
+		Auth:       t.Auth,
+		Tags:       git.NoTags,
+		Force:      cmd.Force,
+	}
+	for _, tag := range *cmd.Tags {
+		ref := tagRefName(tag.String()).String()
+		refSpec := ref + ":" + ref
+		if cmd.Force {
+			refSpec = "+" + refSpec
+		}
+		options.RefSpecs = append(options.RefSpecs, config.RefSpec(refSpec))
+	}
+	err = remote.Fetch(options)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
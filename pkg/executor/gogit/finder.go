@@ -0,0 +1,23 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type CommitFinder struct {
+	Repository *git.Repository
+}
+
+func (c *CommitFinder) Execute(query usecase.FindCommitQuery) (*domain.CommitId, error) {
+	hash, err := c.Repository.ResolveRevision(plumbing.Revision(query.Tag.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", query.Tag.String(), err)
+	}
+	result := domain.CommitId(hash.String())
+	return &result, nil
+}
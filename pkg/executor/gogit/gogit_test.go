@@ -0,0 +1,329 @@
+package gogit_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor/gogit"
+	"msgtm/pkg/usecase"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var signature = &object.Signature{Name: "msgtm", Email: "msgtm@example.com", When: time.Unix(0, 0)}
+
+func initRepository(t *testing.T) (*git.Repository, domain.CommitId) {
+	t.Helper()
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("init", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            signature,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, domain.CommitId(hash.String())
+}
+
+func serviceTags(tags ...string) *[]*domain.ServiceTagWithSemVer {
+	result := []*domain.ServiceTagWithSemVer{}
+	for _, tag := range tags {
+		serviceTag, _ := domain.GitTag(tag).ToServiceTag()
+		result = append(result, serviceTag)
+	}
+	return &result
+}
+
+func sorted(tags *[]domain.GitTag) []domain.GitTag {
+	result := append([]domain.GitTag{}, *tags...)
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func TestRegisterAndQuery(t *testing.T) {
+	repo, commitId := initRepository(t)
+	// a lightweight tag that is not a service tag must be ignored by the list
+	if _, err := repo.CreateTag("release", plumbing.NewHash(commitId.String()), nil); err != nil {
+		t.Fatal(err)
+	}
+	register := gogit.NewTagRegister(repo)
+	register.Tagger = signature
+	head := domain.HEAD
+	err := register.Execute(usecase.RegisterServiceTagsCommand{
+		CommitId: &head,
+		Tags:     serviceTags("service-a-v1.0.0", "service-b-v0.1.0"),
+	})
+	if err != nil {
+		t.Fatalf("register error = %v", err)
+	}
+
+	list := &gogit.TagList{Repository: repo}
+	tags, err := list.Execute(usecase.ListTagsQuery{Filter: func(*domain.ServiceName) bool { return true }})
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	want := []domain.GitTag{"service-a-v1.0.0", "service-b-v0.1.0"}
+	if !reflect.DeepEqual(sorted(tags), want) {
+		t.Errorf("list = %v, want %v", sorted(tags), want)
+	}
+
+	getter := &gogit.CommitTagGetter{Repository: repo}
+	tags, err = getter.Execute(usecase.GetCommitTagQuery{CommitId: &commitId})
+	if err != nil {
+		t.Fatalf("getter error = %v", err)
+	}
+	want = []domain.GitTag{"release", "service-a-v1.0.0", "service-b-v0.1.0"}
+	if !reflect.DeepEqual(sorted(tags), want) {
+		t.Errorf("getter = %v, want %v", sorted(tags), want)
+	}
+
+	finder := &gogit.CommitFinder{Repository: repo}
+	tag := domain.GitTag("service-a-v1.0.0")
+	found, err := finder.Execute(usecase.FindCommitQuery{Tag: &tag})
+	if err != nil {
+		t.Fatalf("finder error = %v", err)
+	}
+	if *found != commitId {
+		t.Errorf("finder = %s, want %s (annotated tags must be peeled)", *found, commitId)
+	}
+
+	destroyer := &gogit.LocalServiceTagsDestroyer{Repository: repo}
+	err = destroyer.Execute(usecase.DestroyServiceTagsCommand{Tags: serviceTags("service-a-v1.0.0")})
+	if err != nil {
+		t.Fatalf("destroyer error = %v", err)
+	}
+	tags, _ = list.Execute(usecase.ListTagsQuery{Filter: func(*domain.ServiceName) bool { return true }})
+	want = []domain.GitTag{"service-b-v0.1.0"}
+	if !reflect.DeepEqual(sorted(tags), want) {
+		t.Errorf("list after destroy = %v, want %v", sorted(tags), want)
+	}
+}
+
+func TestTagRefList(t *testing.T) {
+	repo, commitId := initRepository(t)
+	hash := plumbing.NewHash(commitId.String())
+	if _, err := repo.CreateTag("service-a-v1.0.0", hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateTag("service-a-gateway-v1.0.0", hash, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := repo.CreateTag("service-a-v1.1.0", hash, &git.CreateTagOptions{Tagger: signature, Message: "release service-a\n\nbody"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := &gogit.TagRefList{Repository: repo}
+	refs, err := list.Execute(usecase.ListTagRefsQuery{Services: []domain.ServiceName{"service-a"}})
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	sort.Slice(*refs, func(i, j int) bool { return (*refs)[i].Tag < (*refs)[j].Tag })
+	want := []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: commitId, Type: domain.LightweightTag, TaggerDate: signature.When, Subject: "init"},
+		{Tag: "service-a-v1.1.0", CommitId: commitId, Type: domain.AnnotatedTag, TaggerDate: signature.When, Subject: "release service-a"},
+	}
+	if len(*refs) != len(want) {
+		t.Fatalf("list = %v, want %v", *refs, want)
+	}
+	for i := range want {
+		got := (*refs)[i]
+		if got.Tag != want[i].Tag || got.CommitId != want[i].CommitId || got.Type != want[i].Type ||
+			!got.TaggerDate.Equal(want[i].TaggerDate) || got.Subject != want[i].Subject {
+			t.Errorf("list[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestAncestorChecker(t *testing.T) {
+	repo, first := initRepository(t)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the branch of initRepository is master
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/release", plumbing.NewHash(first.String()))); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("second", &git.CommitOptions{AllowEmptyCommits: true, Author: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := domain.CommitId(hash.String())
+
+	checker := &gogit.AncestorChecker{Repository: repo}
+	tests := []struct {
+		commitId domain.CommitId
+		revision string
+		want     bool
+	}{
+		{first, "release", true},
+		{first, "master", true},
+		{second, "release", false},
+		{second, "master", true},
+		{first, "missing", false},
+	}
+	for _, tt := range tests {
+		got, err := checker.Execute(usecase.IsAncestorQuery{CommitId: &tt.commitId, Revision: tt.revision})
+		if err != nil {
+			t.Fatalf("Execute(%s, %s) error = %v, want nil", tt.commitId, tt.revision, err)
+		}
+		if got != tt.want {
+			t.Errorf("Execute(%s, %s) = %v, want %v", tt.commitId, tt.revision, got, tt.want)
+		}
+	}
+}
+
+func TestCommitList(t *testing.T) {
+	repo, first := initRepository(t)
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(message string, seconds int64) domain.CommitId {
+		author := &object.Signature{Name: signature.Name, Email: signature.Email, When: time.Unix(seconds, 0)}
+		hash, err := worktree.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: author})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return domain.CommitId(hash.String())
+	}
+	second := commit("second\n\nbody", 1)
+	third := commit("third", 2)
+
+	list := &gogit.CommitList{Repository: repo}
+	commits, err := list.Execute(usecase.ListCommitsQuery{From: &first, To: &third})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want := []domain.Commit{{Id: third, Subject: "third"}, {Id: second, Subject: "second"}}
+	if !reflect.DeepEqual(*commits, want) {
+		t.Errorf("Execute() = %v, want %v", *commits, want)
+	}
+	commits, err = list.Execute(usecase.ListCommitsQuery{To: &second})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if len(*commits) != 2 {
+		t.Errorf("Execute() without From = %v, want 2 commits", *commits)
+	}
+}
+
+func TestCommitter(t *testing.T) {
+	repo, first := initRepository(t)
+	root, err := gogit.RepositoryRoot(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "api", "VERSION")
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	committer := &gogit.Committer{Author: signature, Repository: repo}
+	err = committer.Execute(usecase.CreateCommitCommand{Message: "Release api-v1.0.0", Files: []string{file}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Message != "Release api-v1.0.0" || commit.ParentHashes[0].String() != string(first) {
+		t.Errorf("HEAD = %q on %s, want the release commit on %s", commit.Message, commit.ParentHashes[0], first)
+	}
+	if _, err := commit.File("api/VERSION"); err != nil {
+		t.Errorf("release commit has no api/VERSION: %v", err)
+	}
+}
+
+func TestCommitterRefusesOtherStagedChanges(t *testing.T) {
+	repo, first := initRepository(t)
+	root, err := gogit.RepositoryRoot(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"VERSION", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("1.0.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("other.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	committer := &gogit.Committer{Author: signature, Repository: repo}
+	err = committer.Execute(usecase.CreateCommitCommand{Message: "Release api-v1.0.0", Files: []string{filepath.Join(root, "VERSION")}})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want an error for the staged other.txt")
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash().String() != string(first) {
+		t.Errorf("HEAD = %s, want %s without a commit", head.Hash(), first)
+	}
+}
+
+func TestCommitResetter(t *testing.T) {
+	repo, first := initRepository(t)
+	root, err := gogit.RepositoryRoot(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "VERSION")
+	if err := os.WriteFile(file, []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	committer := &gogit.Committer{Author: signature, Repository: repo}
+	if err := committer.Execute(usecase.CreateCommitCommand{Message: "Release api-v1.0.0", Files: []string{file}}); err != nil {
+		t.Fatal(err)
+	}
+
+	resetter := &gogit.CommitResetter{Repository: repo}
+	if err := resetter.Execute(usecase.ResetCommitCommand{CommitId: &first, Files: []string{file}}); err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash().String() != string(first) {
+		t.Errorf("HEAD = %s, want %s", head.Hash(), first)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileStatus := status.File("VERSION"); fileStatus.Staging != git.Untracked {
+		t.Errorf("VERSION staging = %q, want untracked with the working tree kept", fileStatus.Staging)
+	}
+}
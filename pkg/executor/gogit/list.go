@@ -0,0 +1,36 @@
+package gogit
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type TagList struct {
+	Repository *git.Repository
+}
+
+func (t *TagList) Execute(query usecase.ListTagsQuery) (*[]domain.GitTag, error) {
+	refs, err := t.Repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	filteredTags := []domain.GitTag{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := domain.GitTag(ref.Name().Short())
+		serviceTag, err := tag.ToServiceTag()
+		if err != nil {
+			return nil
+		}
+		if query.Filter(&serviceTag.Service) {
+			filteredTags = append(filteredTags, tag)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &filteredTags, nil
+}
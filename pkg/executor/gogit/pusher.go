@@ -0,0 +1,22 @@
+package gogit
+
+import (
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+type TagPusher struct {
+	Auth       transport.AuthMethod
+	Repository *git.Repository
+}
+
+func (t *TagPusher) Execute(cmd usecase.CommitPushCommand) error {
+	refSpecs := []string{}
+	for _, tag := range *cmd.Tags {
+		ref := tagRefName(tag.String()).String()
+		refSpecs = append(refSpecs, ref+":"+ref)
+	}
+	return pushRefSpecs(t.Repository, cmd.RemoteAddr.String(), t.Auth, cmd.Atomic, refSpecs)
+}
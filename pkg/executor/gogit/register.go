@@ -0,0 +1,54 @@
+package gogit
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type TagRegister struct {
+	f func(*domain.CommitId, *domain.ServiceTagWithSemVer) string
+	// Tagger of annotated tags, the user of the git config is used when nil.
+	Tagger     *object.Signature
+	Repository *git.Repository
+}
+
+// NewTagRegister creates annotated tags with the message made by opt,
+// in the same way as executor.NewGitTagRegister.
+func NewTagRegister(repo *git.Repository, opt ...func(*domain.CommitId, *domain.ServiceTagWithSemVer) string) *TagRegister {
+	f := func(commitId *domain.CommitId, tag *domain.ServiceTagWithSemVer) string {
+		return fmt.Sprintf("Add %s tags to %s", tag.String(), commitId.String())
+	}
+	if len(opt) > 0 {
+		f = opt[0]
+	}
+	return &TagRegister{
+		f:          f,
+		Repository: repo,
+	}
+}
+
+func (t *TagRegister) Execute(cmd usecase.RegisterServiceTagsCommand) error {
+	hash, err := t.Repository.ResolveRevision(plumbing.Revision(cmd.CommitId.String()))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", cmd.CommitId.String(), err)
+	}
+	for _, tag := range *cmd.Tags {
+		var options *git.CreateTagOptions
+		if t.f != nil {
+			options = &git.CreateTagOptions{
+				Tagger:  t.Tagger,
+				Message: t.f(cmd.CommitId, tag),
+			}
+		}
+		_, err := t.Repository.CreateTag(tag.String(), *hash, options)
+		if err != nil {
+			return fmt.Errorf("failed to create tag %s: %w", tag.String(), err)
+		}
+	}
+	return nil
+}
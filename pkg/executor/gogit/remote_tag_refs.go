@@ -0,0 +1,57 @@
+package gogit
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+type RemoteTagRefList struct {
+	Auth       transport.AuthMethod
+	Repository *git.Repository
+}
+
+func (r *RemoteTagRefList) Execute(query usecase.ListRemoteTagRefsQuery) (*[]domain.TagRef, error) {
+	remote, err := remoteOf(r.Repository, query.RemoteAddr.String())
+	if err != nil {
+		return nil, err
+	}
+	advertised, err := remote.List(&git.ListOptions{
+		Auth:          r.Auth,
+		PeelingOption: git.AppendPeeled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	refs := []domain.TagRef{}
+	peeled := map[string]string{}
+	for _, ref := range advertised {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		tag, isPeeled := strings.CutSuffix(ref.Name().Short(), "^{}")
+		if isPeeled {
+			peeled[tag] = ref.Hash().String()
+			continue
+		}
+		tagRef := domain.TagRef{
+			Tag:      domain.GitTag(tag),
+			CommitId: domain.CommitId(ref.Hash().String()),
+			Type:     domain.LightweightTag,
+		}
+		if len(query.Services) > 0 && !tagRef.IsServiceTagOf(query.Services) {
+			continue
+		}
+		refs = append(refs, tagRef)
+	}
+	for i, ref := range refs {
+		if commitId, ok := peeled[ref.Tag.String()]; ok {
+			refs[i].CommitId = domain.CommitId(commitId)
+			refs[i].Type = domain.AnnotatedTag
+		}
+	}
+	return &refs, nil
+}
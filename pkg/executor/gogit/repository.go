@@ -0,0 +1,106 @@
+package gogit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// OpenRepository opens the repository containing dir without the git binary.
+func OpenRepository(dir string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+}
+
+// RepositoryRoot returns the top level directory of the working tree of repo.
+func RepositoryRoot(repo *git.Repository) (string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(worktree.Filesystem.Root())
+}
+
+func tagRefName(tag string) plumbing.ReferenceName {
+	return plumbing.NewTagReferenceName(tag)
+}
+
+// peel resolves a tag reference to the commit it points at.
+// Annotated tags point at a tag object, lightweight tags point at the commit directly.
+func peel(repo *git.Repository, ref *plumbing.Reference) (plumbing.Hash, error) {
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return ref.Hash(), nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	commit, err := tag.Commit()
+	if errors.Is(err, object.ErrUnsupportedObject) {
+		// a tag of a tree or a blob is not a service tag target
+		return tag.Target, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return commit.Hash, nil
+}
+
+// remoteOf returns the remote named remote, or an anonymous remote
+// when remote is a url, like the remote argument of git push and git ls-remote.
+func remoteOf(repo *git.Repository, remote string) (*git.Remote, error) {
+	r, err := repo.Remote(remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return git.NewRemote(repo.Storer, &config.RemoteConfig{
+			Name: "anonymous",
+			URLs: []string{remote},
+		}), nil
+	}
+	return r, err
+}
+
+func pushRefSpecs(repo *git.Repository, remote string, auth transport.AuthMethod, atomic bool, refSpecs []string) error {
+	r, err := remoteOf(repo, remote)
+	if err != nil {
+		return err
+	}
+	options := &git.PushOptions{
+		RemoteName: r.Config().Name,
+		Auth:       auth,
+		Atomic:     atomic,
+	}
+	for _, refSpec := range refSpecs {
+		options.RefSpecs = append(options.RefSpecs, config.RefSpec(refSpec))
+	}
+	err = r.Push(options)
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// AuthFromEnv returns http basic auth built from MSGTM_GIT_USERNAME and MSGTM_GIT_TOKEN.
+// It returns nil when no token is set, in which case ssh remotes use the ssh agent.
+func AuthFromEnv() transport.AuthMethod {
+	token := os.Getenv("MSGTM_GIT_TOKEN")
+	if token == "" {
+		return nil
+	}
+	username := os.Getenv("MSGTM_GIT_USERNAME")
+	if username == "" {
+		// most hosting services accept any non empty user name with a token
+		username = "msgtm"
+	}
+	return &http.BasicAuth{
+		Username: username,
+		Password: token,
+	}
+}
@@ -0,0 +1,73 @@
+package gogit
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type TagRefList struct {
+	Repository *git.Repository
+}
+
+func (t *TagRefList) Execute(query usecase.ListTagRefsQuery) (*[]domain.TagRef, error) {
+	refs, err := t.Repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.TagRef{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tagRef := domain.TagRef{Tag: domain.GitTag(ref.Name().Short())}
+		if len(query.Services) > 0 && !tagRef.IsServiceTagOf(query.Services) {
+			return nil
+		}
+		if err := t.describe(ref, &tagRef); err != nil {
+			return err
+		}
+		result = append(result, tagRef)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (t *TagRefList) describe(ref *plumbing.Reference, tagRef *domain.TagRef) error {
+	tag, err := t.Repository.TagObject(ref.Hash())
+	if err == nil {
+		commitId, err := peel(t.Repository, ref)
+		if err != nil {
+			return err
+		}
+		tagRef.Type = domain.AnnotatedTag
+		tagRef.CommitId = domain.CommitId(commitId.String())
+		tagRef.TaggerDate = tag.Tagger.When
+		tagRef.Subject = subject(tag.Message)
+		return nil
+	}
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	tagRef.Type = domain.LightweightTag
+	tagRef.CommitId = domain.CommitId(ref.Hash().String())
+	commit, err := t.Repository.CommitObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		// a lightweight tag of a tree or a blob has no date nor subject
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tagRef.TaggerDate = commit.Committer.When
+	tagRef.Subject = subject(commit.Message)
+	return nil
+}
+
+func subject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
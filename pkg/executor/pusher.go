@@ -11,7 +11,7 @@ func (g *GitTagPusher) Execute(cmd usecase.CommitPushCommand) error {
 	for _, tag := range *cmd.Tags {
 		tagStrs = append(tagStrs, tag.String())
 	}
-	_, err := gitPushTags(g.GitCommandExecutor, cmd.RemoteAddr.String(), tagStrs...)
+	_, err := gitPushTags(g.GitCommandExecutor, cmd.RemoteAddr.String(), cmd.Atomic, tagStrs...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strings"
+)
+
+type GitRemoteTagRefList struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitRemoteTagRefList) Execute(query usecase.ListRemoteTagRefsQuery) (*[]domain.TagRef, error) {
+	patterns := []string{}
+	for _, service := range query.Services {
+		patterns = append(patterns, fmt.Sprintf("refs/tags/%s-*", service.String()))
+	}
+	output, err := gitLsRemoteTags(g.GitCommandExecutor, query.RemoteAddr.String(), patterns...)
+	if err != nil {
+		return nil, err
+	}
+	refs := []domain.TagRef{}
+	// annotated tags are listed twice, the tag object and the commit suffixed by ^{}
+	indexes := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		objectId, refName, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected ls-remote output: %q", line)
+		}
+		tag, isPeeled := strings.CutSuffix(strings.TrimPrefix(refName, "refs/tags/"), "^{}")
+		if i, ok := indexes[tag]; ok && isPeeled {
+			refs[i].CommitId = domain.CommitId(objectId)
+			refs[i].Type = domain.AnnotatedTag
+			continue
+		}
+		ref := domain.TagRef{
+			Tag:      domain.GitTag(tag),
+			CommitId: domain.CommitId(objectId),
+			Type:     domain.LightweightTag,
+		}
+		if len(query.Services) > 0 && !ref.IsServiceTagOf(query.Services) {
+			continue
+		}
+		indexes[tag] = len(refs)
+		refs = append(refs, ref)
+	}
+	return &refs, nil
+}
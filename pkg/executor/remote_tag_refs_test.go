@@ -0,0 +1,43 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGitRemoteTagRefList(t *testing.T) {
+	r := newTestRepository(t)
+	r.addRemote("origin")
+	first := r.commit("first commit")
+	r.git("tag", "service-a-v1.0.0")
+	r.git("tag", "service-a-gateway-v1.0.0")
+	second := r.commit("second commit")
+	r.git("tag", "-a", "service-a-v1.1.0", "-m", "release service-a")
+	r.git("push", "--quiet", "origin", "--tags")
+	// local only tags must not be listed
+	r.git("tag", "service-a-v1.2.0")
+
+	list := &executor.GitRemoteTagRefList{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	origin := domain.Origin
+	refs, err := list.Execute(usecase.ListRemoteTagRefsQuery{
+		RemoteAddr: &origin,
+		Services:   []domain.ServiceName{"service-a"},
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	sort.Slice(*refs, func(i, j int) bool { return (*refs)[i].Tag < (*refs)[j].Tag })
+	want := []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: domain.CommitId(first), Type: domain.LightweightTag},
+		{Tag: "service-a-v1.1.0", CommitId: domain.CommitId(second), Type: domain.AnnotatedTag},
+	}
+	if !reflect.DeepEqual(*refs, want) {
+		t.Errorf("Execute() = %v, want %v", *refs, want)
+	}
+}
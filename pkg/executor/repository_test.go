@@ -0,0 +1,38 @@
+package executor_test
+
+import (
+	"msgtm/pkg/executor"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitShellCommandExecutorIn(t *testing.T) {
+	r := newTestRepository(t)
+	commitId := r.commit("first commit")
+	sub := filepath.Join(r.dir, "services", "api")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// run from outside of the repository
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	git := executor.GitShellCommandExecutorIn(sub)
+	root, err := executor.RepositoryRoot(git)
+	if err != nil {
+		t.Fatalf("RepositoryRoot() error = %v, want nil", err)
+	}
+	want, _ := filepath.EvalSymlinks(r.dir)
+	if got, _ := filepath.EvalSymlinks(root); got != want {
+		t.Errorf("RepositoryRoot() = %s, want %s", root, want)
+	}
+	head, err := git("rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("git rev-parse error = %v, want nil", err)
+	}
+	if head != commitId+"\n" {
+		t.Errorf("git rev-parse HEAD = %q, want %s", head, commitId)
+	}
+}
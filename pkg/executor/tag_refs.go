@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GitTagRefList struct {
+	GitCommandExecutor GitCommandExecutor
+}
+
+func (g *GitTagRefList) Execute(query usecase.ListTagRefsQuery) (*[]domain.TagRef, error) {
+	patterns := []string{}
+	for _, service := range query.Services {
+		patterns = append(patterns, fmt.Sprintf("refs/tags/%s-*", service.String()))
+	}
+	output, err := gitForEachTagRef(g.GitCommandExecutor, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := parseTagRefs(output)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.Services) == 0 {
+		return &refs, nil
+	}
+	// service-* also matches the tags of service-gateway
+	filtered := []domain.TagRef{}
+	for _, ref := range refs {
+		if ref.IsServiceTagOf(query.Services) {
+			filtered = append(filtered, ref)
+		}
+	}
+	return &filtered, nil
+}
+
+func parseTagRefs(output string) ([]domain.TagRef, error) {
+	refs := []domain.TagRef{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 6)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("unexpected for-each-ref output: %q", line)
+		}
+		ref := domain.TagRef{
+			Tag:      domain.GitTag(fields[0]),
+			CommitId: domain.CommitId(fields[2]),
+			Type:     domain.LightweightTag,
+			Subject:  fields[5],
+		}
+		if fields[1] == "tag" {
+			ref.Type = domain.AnnotatedTag
+			ref.CommitId = domain.CommitId(fields[3])
+		}
+		if fields[4] != "" {
+			unix, err := strconv.ParseInt(fields[4], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected tagger date of %s: %w", fields[0], err)
+			}
+			ref.TaggerDate = time.Unix(unix, 0)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
@@ -0,0 +1,51 @@
+package executor_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/executor"
+	"msgtm/pkg/usecase"
+	"sort"
+	"testing"
+)
+
+func TestGitTagRefList(t *testing.T) {
+	r := newTestRepository(t)
+	first := r.commit("first commit")
+	r.git("tag", "service-a-v1.0.0")
+	r.git("tag", "service-a-gateway-v1.0.0")
+	second := r.commit("second commit")
+	r.git("tag", "-a", "service-a-v1.1.0", "-m", "release service-a")
+	r.git("tag", "service-b-v0.1.0")
+
+	list := &executor.GitTagRefList{
+		GitCommandExecutor: executor.GitShellCommandExecutor(),
+	}
+	refs, err := list.Execute(usecase.ListTagRefsQuery{Services: []domain.ServiceName{"service-a"}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	sort.Slice(*refs, func(i, j int) bool { return (*refs)[i].Tag < (*refs)[j].Tag })
+	want := []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: domain.CommitId(first), Type: domain.LightweightTag, Subject: "first commit"},
+		{Tag: "service-a-v1.1.0", CommitId: domain.CommitId(second), Type: domain.AnnotatedTag, Subject: "release service-a"},
+	}
+	if len(*refs) != len(want) {
+		t.Fatalf("Execute() = %v, want %v", *refs, want)
+	}
+	for i, ref := range *refs {
+		if ref.Tag != want[i].Tag || ref.CommitId != want[i].CommitId || ref.Type != want[i].Type || ref.Subject != want[i].Subject {
+			t.Errorf("Execute()[%d] = %+v, want %+v", i, ref, want[i])
+		}
+		if ref.TaggerDate.IsZero() {
+			t.Errorf("Execute()[%d].TaggerDate is zero", i)
+		}
+	}
+
+	all, err := list.Execute(usecase.ListTagRefsQuery{})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if len(*all) != 4 {
+		t.Errorf("Execute() without services = %v, want 4 tags", *all)
+	}
+}
@@ -0,0 +1,66 @@
+package executor_test
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// testRepository is a git repository in a temporary directory.
+// The working directory is moved into it for the duration of the test,
+// since GitShellCommandExecutor runs git in the working directory.
+type testRepository struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepository(t *testing.T) *testRepository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+	r := &testRepository{t: t, dir: dir}
+	r.git("init", "--quiet", "--initial-branch=main")
+	r.git("config", "user.name", "msgtm")
+	r.git("config", "user.email", "msgtm@example.com")
+	r.git("config", "commit.gpgsign", "false")
+	r.git("config", "tag.gpgsign", "false")
+	return r
+}
+
+func (r *testRepository) git(args ...string) string {
+	r.t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commit creates an empty commit and returns its id.
+func (r *testRepository) commit(message string) string {
+	r.t.Helper()
+	r.git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.git("rev-parse", "HEAD")
+}
+
+// addRemote creates a bare repository and adds it as a remote named name.
+func (r *testRepository) addRemote(name string) string {
+	r.t.Helper()
+	dir := r.t.TempDir()
+	r.git("init", "--quiet", "--bare", dir)
+	r.git("remote", "add", name, dir)
+	return dir
+}
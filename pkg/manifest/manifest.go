@@ -0,0 +1,298 @@
+// Package manifest rewrites values of YAML, JSON and TOML files in place.
+// The documents are parsed only to find the positions of the values, which are replaced in the original text,
+// so that comments, indentation and quoting are preserved.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change is a value replaced in a file.
+type Change struct {
+	Line int
+	// Where is the path of the value, e.g. image.tag or services.api.image.
+	Where string
+	Old   string
+	New   string
+}
+
+// edit replaces a scalar node with a value.
+type edit struct {
+	node  *yaml.Node
+	where string
+	value string
+}
+
+// SetPath sets the value at path in every document of data, e.g. image.tag or spec.containers[0].image.
+// A path that is in none of the documents is an error.
+func SetPath(data []byte, path string, value string) ([]byte, []Change, error) {
+	keys, err := parsePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs, err := parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	edits := []edit{}
+	for _, doc := range docs {
+		if node := lookup(doc, keys); node != nil {
+			edits = append(edits, edit{node: node, where: path, value: value})
+		}
+	}
+	if len(edits) == 0 {
+		return nil, nil, fmt.Errorf("%s not found", path)
+	}
+	return apply(data, edits)
+}
+
+// SetImageTag sets the tag of every reference to image in data:
+// the newTag of the images of kustomizations whose name or newName is image,
+// and the values of image keys, e.g. of docker-compose services or helm values, that are image or image:tag.
+// A file without the image is an error.
+func SetImageTag(data []byte, image string, tag string) ([]byte, []Change, error) {
+	docs, err := parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	edits := []edit{}
+	for _, doc := range docs {
+		walk(doc, "", func(node *yaml.Node, where string) {
+			if node.Kind != yaml.MappingNode {
+				return
+			}
+			name, newName, newTag := value(node, "name"), value(node, "newName"), value(node, "newTag")
+			if newTag != nil && ((name != nil && name.Value == image) || (newName != nil && newName.Value == image)) {
+				edits = append(edits, edit{node: newTag, where: join(where, "newTag"), value: tag})
+			}
+			if ref := value(node, "image"); ref != nil && ref.Kind == yaml.ScalarNode {
+				repository, _ := splitImage(ref.Value)
+				if repository == image {
+					edits = append(edits, edit{node: ref, where: join(where, "image"), value: image + ":" + tag})
+				}
+			}
+		})
+	}
+	if len(edits) == 0 {
+		return nil, nil, fmt.Errorf("image %s not found", image)
+	}
+	return apply(data, edits)
+}
+
+// splitImage splits image:tag, the port of a registry is not a tag, e.g. localhost:5000/api.
+func splitImage(ref string) (string, string) {
+	ref, _, _ = strings.Cut(ref, "@")
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return ref, ""
+	}
+	return ref[:i], ref[i+1:]
+}
+
+func parse(data []byte) ([]*yaml.Node, error) {
+	docs := []*yaml.Node{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+// parsePath splits a.b[0].c into a, b, [0] and c.
+func parsePath(path string) ([]string, error) {
+	keys := []string{}
+	for _, part := range strings.Split(path, ".") {
+		key, index, indexed := strings.Cut(part, "[")
+		if key == "" && !indexed {
+			return nil, fmt.Errorf("invalid path %s", path)
+		}
+		if key != "" {
+			keys = append(keys, key)
+		}
+		for indexed {
+			var rest string
+			index, rest, _ = strings.Cut(index, "]")
+			if _, err := strconv.Atoi(index); err != nil {
+				return nil, fmt.Errorf("invalid index [%s] of path %s", index, path)
+			}
+			keys = append(keys, "["+index+"]")
+			_, index, indexed = strings.Cut(rest, "[")
+		}
+	}
+	return keys, nil
+}
+
+func lookup(node *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		switch {
+		case node.Kind == yaml.SequenceNode && strings.HasPrefix(key, "["):
+			i, _ := strconv.Atoi(strings.Trim(key, "[]"))
+			if i < 0 || i >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[i]
+		case node.Kind == yaml.MappingNode:
+			node = value(node, key)
+			if node == nil {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return nil
+	}
+	return node
+}
+
+// value returns the value of key in a mapping.
+func value(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func walk(node *yaml.Node, where string, visit func(node *yaml.Node, where string)) {
+	visit(node, where)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walk(node.Content[i+1], join(where, node.Content[i].Value), visit)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walk(child, fmt.Sprintf("%s[%d]", where, i), visit)
+		}
+	}
+}
+
+func join(where string, key string) string {
+	if where == "" {
+		return key
+	}
+	return where + "." + key
+}
+
+// apply replaces the text of the nodes of edits, in the quoting style of the original values.
+func apply(data []byte, edits []edit) ([]byte, []Change, error) {
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i].node, edits[j].node
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+	lines := lineOffsets(data)
+	result := data
+	changes := []Change{}
+	// the edits are applied from the end of the file, so that the offsets of the others stay valid
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		if e.node.Value == e.value {
+			continue
+		}
+		start, end, err := extent(data, lines, e.node)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %s: %w", e.node.Line, e.where, err)
+		}
+		replaced := make([]byte, 0, len(result)+len(e.value))
+		replaced = append(replaced, result[:start]...)
+		replaced = append(replaced, render(e.node.Style, e.value)...)
+		replaced = append(replaced, result[end:]...)
+		result = replaced
+		changes = append([]Change{{Line: e.node.Line, Where: e.where, Old: e.node.Value, New: e.value}}, changes...)
+	}
+	return result, changes, nil
+}
+
+func lineOffsets(data []byte) []int {
+	offsets := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// extent returns the byte range of the text of a scalar node.
+func extent(data []byte, lines []int, node *yaml.Node) (int, int, error) {
+	if node.Line < 1 || node.Line > len(lines) {
+		return 0, 0, errors.New("position out of the file")
+	}
+	start := lines[node.Line-1]
+	// the column counts characters, not bytes
+	for column := 1; column < node.Column && start < len(data); column++ {
+		_, size := utf8.DecodeRune(data[start:])
+		start += size
+	}
+	text := data[start:]
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, start + i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return start, start + i + 1, nil
+		}
+	case 0:
+		if bytes.HasPrefix(text, []byte(node.Value)) {
+			return start, start + len(node.Value), nil
+		}
+	default:
+		return 0, 0, errors.New("block scalars, tagged and flow values can not be replaced")
+	}
+	return 0, 0, errors.New("the value does not match the text of the file")
+}
+
+// render writes value in style, a plain value that would not read as the same string is double quoted.
+func render(style yaml.Style, value string) string {
+	switch style {
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(value)
+	}
+	parsed := map[string]any{}
+	if yaml.Unmarshal([]byte("v: "+value), &parsed) == nil {
+		if s, ok := parsed["v"].(string); ok && s == value && !strings.ContainsAny(value, "\n#") {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
@@ -0,0 +1,127 @@
+package manifest_test
+
+import (
+	"msgtm/pkg/manifest"
+	"testing"
+)
+
+func TestSetPath(t *testing.T) {
+	values := `# values of api
+image:
+  repository: registry.example.com/api
+  tag: "v1.1.0" # updated by msgtm
+replicas: 2
+sidecars:
+  - name: proxy
+    tag: 'v0.9.0'
+`
+	updated, changes, err := manifest.SetPath([]byte(values), "image.tag", "v1.2.0")
+	if err != nil {
+		t.Fatalf("SetPath() error = %v, want nil", err)
+	}
+	want := `# values of api
+image:
+  repository: registry.example.com/api
+  tag: "v1.2.0" # updated by msgtm
+replicas: 2
+sidecars:
+  - name: proxy
+    tag: 'v0.9.0'
+`
+	if string(updated) != want {
+		t.Errorf("SetPath() =\n%s\nwant\n%s", updated, want)
+	}
+	if len(changes) != 1 || changes[0].Line != 4 || changes[0].Old != "v1.1.0" || changes[0].New != "v1.2.0" {
+		t.Errorf("changes = %+v, want v1.1.0 -> v1.2.0 at line 4", changes)
+	}
+
+	updated, _, err = manifest.SetPath([]byte(values), "sidecars[0].tag", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "    tag: 'v1.0.0'\n"; string(updated[len(updated)-len(want):]) != want {
+		t.Errorf("SetPath() of a sequence =\n%s", updated)
+	}
+
+	// a plain value that would read as a number is quoted
+	updated, _, err = manifest.SetPath([]byte("version: latest\n"), "version", "1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(updated) != "version: \"1.2\"\n" {
+		t.Errorf("SetPath() = %q, want the number quoted", updated)
+	}
+
+	unchanged, changes, err := manifest.SetPath([]byte(want), "image.tag", "v1.2.0")
+	if err != nil || len(changes) != 0 || string(unchanged) != want {
+		t.Errorf("SetPath() of the current value = %d changes, %v, want no change", len(changes), err)
+	}
+	if _, _, err := manifest.SetPath([]byte(values), "image.digest", "v1.2.0"); err == nil {
+		t.Error("SetPath() of a missing path error = nil, want an error")
+	}
+}
+
+func TestSetImageTag(t *testing.T) {
+	kustomization := `resources:
+  - deployment.yaml
+images:
+  - name: api
+    newName: registry.example.com/api
+    newTag: v1.1.0 # api
+  - name: registry.example.com/web
+    newTag: v0.1.0
+`
+	updated, changes, err := manifest.SetImageTag([]byte(kustomization), "registry.example.com/api", "v1.2.0")
+	if err != nil {
+		t.Fatalf("SetImageTag() error = %v, want nil", err)
+	}
+	want := `resources:
+  - deployment.yaml
+images:
+  - name: api
+    newName: registry.example.com/api
+    newTag: v1.2.0 # api
+  - name: registry.example.com/web
+    newTag: v0.1.0
+`
+	if string(updated) != want {
+		t.Errorf("SetImageTag() =\n%s\nwant\n%s", updated, want)
+	}
+	if len(changes) != 1 || changes[0].Where != "images[0].newTag" {
+		t.Errorf("changes = %+v, want images[0].newTag", changes)
+	}
+
+	compose := `services:
+  api:
+    image: localhost:5000/api:v1.1.0
+  worker:
+    image: "localhost:5000/api"   # same image
+  web:
+    image: localhost:5000/web:v0.1.0
+---
+image: localhost:5000/api:v1.0.0
+`
+	updated, changes, err = manifest.SetImageTag([]byte(compose), "localhost:5000/api", "v1.2.0")
+	if err != nil {
+		t.Fatalf("SetImageTag() error = %v, want nil", err)
+	}
+	want = `services:
+  api:
+    image: localhost:5000/api:v1.2.0
+  worker:
+    image: "localhost:5000/api:v1.2.0"   # same image
+  web:
+    image: localhost:5000/web:v0.1.0
+---
+image: localhost:5000/api:v1.2.0
+`
+	if string(updated) != want {
+		t.Errorf("SetImageTag() =\n%s\nwant\n%s", updated, want)
+	}
+	if len(changes) != 3 || changes[2].Line != 9 {
+		t.Errorf("changes = %+v, want 3 changes, the last at line 9", changes)
+	}
+	if _, _, err := manifest.SetImageTag([]byte(compose), "localhost:5000/db", "v1.0.0"); err == nil {
+		t.Error("SetImageTag() of a missing image error = nil, want an error")
+	}
+}
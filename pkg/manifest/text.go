@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SetTOMLKey sets the string value of a dotted key, e.g. project.version or tool.poetry.version.
+// The key is looked up in the tables of the file, values of inline tables and arrays are not supported.
+func SetTOMLKey(data []byte, key string, value string) ([]byte, []Change, error) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	table := ""
+	for i, line := range lines {
+		text := strings.TrimSpace(string(line))
+		if strings.HasPrefix(text, "[") {
+			table = strings.Trim(text, "[] ")
+			if comment := strings.Index(table, "#"); comment >= 0 {
+				table = strings.TrimSpace(strings.Trim(table[:comment], "[] "))
+			}
+			continue
+		}
+		name, rest, ok := strings.Cut(text, "=")
+		if !ok || strings.HasPrefix(text, "#") {
+			continue
+		}
+		full := strings.TrimSpace(name)
+		if table != "" {
+			full = table + "." + full
+		}
+		if full != key {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			return nil, nil, fmt.Errorf("line %d: %s is not a string", i+1, key)
+		}
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			return nil, nil, fmt.Errorf("line %d: %s is not a single line string", i+1, key)
+		}
+		old := rest[1 : end+1]
+		if old == value {
+			return data, nil, nil
+		}
+		start := bytes.Index(line, []byte(rest))
+		quoted := rest[:1] + value + rest[:1]
+		if rest[0] == '"' {
+			quoted = strconv.Quote(value)
+		}
+		replaced := append([]byte{}, line[:start]...)
+		replaced = append(replaced, quoted...)
+		replaced = append(replaced, line[start+end+2:]...)
+		lines[i] = replaced
+		return bytes.Join(lines, nil), []Change{{Line: i + 1, Where: key, Old: old, New: value}}, nil
+	}
+	return nil, nil, fmt.Errorf("%s not found", key)
+}
+
+// ReplaceRegex replaces the first match of pattern, or its first group when it has one,
+// e.g. const Version = "(.*)".
+func ReplaceRegex(data []byte, pattern string, value string) ([]byte, []Change, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	match := re.FindSubmatchIndex(data)
+	if match == nil {
+		return nil, nil, fmt.Errorf("%s does not match", pattern)
+	}
+	start, end := match[0], match[1]
+	if len(match) > 2 && match[2] >= 0 {
+		start, end = match[2], match[3]
+	}
+	old := string(data[start:end])
+	if old == value {
+		return data, nil, nil
+	}
+	replaced := append([]byte{}, data[:start]...)
+	replaced = append(replaced, value...)
+	replaced = append(replaced, data[end:]...)
+	line := bytes.Count(data[:start], []byte("\n")) + 1
+	return replaced, []Change{{Line: line, Where: pattern, Old: old, New: value}}, nil
+}
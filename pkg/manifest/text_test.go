@@ -0,0 +1,73 @@
+package manifest_test
+
+import (
+	"msgtm/pkg/manifest"
+	"strings"
+	"testing"
+)
+
+func TestSetTOMLKey(t *testing.T) {
+	pyproject := `[project]
+name = "api"
+version = "1.1.0"  # released by msgtm
+
+[tool.poetry]
+version = '1.1.0'
+`
+	updated, changes, err := manifest.SetTOMLKey([]byte(pyproject), "tool.poetry.version", "1.2.0")
+	if err != nil {
+		t.Fatalf("SetTOMLKey() error = %v, want nil", err)
+	}
+	want := `[project]
+name = "api"
+version = "1.1.0"  # released by msgtm
+
+[tool.poetry]
+version = '1.2.0'
+`
+	if string(updated) != want {
+		t.Errorf("SetTOMLKey() =\n%s\nwant\n%s", updated, want)
+	}
+	if len(changes) != 1 || changes[0].Line != 6 {
+		t.Errorf("changes = %+v, want a change at line 6", changes)
+	}
+
+	updated, _, err = manifest.SetTOMLKey([]byte(pyproject), "project.version", "1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "version = \"1.2.0\"  # released by msgtm\n"; !strings.Contains(string(updated), want) {
+		t.Errorf("SetTOMLKey() =\n%s", updated)
+	}
+	if _, _, err := manifest.SetTOMLKey([]byte(pyproject), "version", "1.2.0"); err == nil {
+		t.Error("SetTOMLKey() of a key outside of the tables error = nil, want an error")
+	}
+}
+
+func TestReplaceRegex(t *testing.T) {
+	source := "package main\n\nconst Version = \"1.1.0\"\n"
+	updated, changes, err := manifest.ReplaceRegex([]byte(source), `const Version = "(.*)"`, "1.2.0")
+	if err != nil {
+		t.Fatalf("ReplaceRegex() error = %v, want nil", err)
+	}
+	if want := "package main\n\nconst Version = \"1.2.0\"\n"; string(updated) != want {
+		t.Errorf("ReplaceRegex() = %q, want %q", updated, want)
+	}
+	if len(changes) != 1 || changes[0].Line != 3 || changes[0].Old != "1.1.0" {
+		t.Errorf("changes = %+v, want 1.1.0 at line 3", changes)
+	}
+	if _, _, err := manifest.ReplaceRegex([]byte(source), `VERSION = (.*)`, "1.2.0"); err == nil {
+		t.Error("ReplaceRegex() without a match error = nil, want an error")
+	}
+}
+
+func TestSetPathOfJSON(t *testing.T) {
+	pkg := "{\n  \"name\": \"web\",\n  \"version\": \"0.1.0\",\n  \"private\": true\n}\n"
+	updated, _, err := manifest.SetPath([]byte(pkg), "version", "0.2.0")
+	if err != nil {
+		t.Fatalf("SetPath() error = %v, want nil", err)
+	}
+	if want := "{\n  \"name\": \"web\",\n  \"version\": \"0.2.0\",\n  \"private\": true\n}\n"; string(updated) != want {
+		t.Errorf("SetPath() = %q, want %q", updated, want)
+	}
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"encoding/json"
+	"msgtm/pkg/usecase"
+	"net/http"
+)
+
+const (
+	// DefaultSlackTemplate is Slack mrkdwn, e.g. *api v1.2.3* released at `0123456` (previous v1.2.2).
+	DefaultSlackTemplate = "*{{.Name}}* released at `{{.ShortCommit}}`{{if .PreviousVersion}} (previous {{.PreviousVersion}}){{end}}\n" +
+		"{{range .Changelog}}• {{.Subject}} (`{{short .Id}}`)\n{{end}}"
+	// DefaultTeamsTemplate is the text of the card, whose title is the name of the release.
+	DefaultTeamsTemplate = "{{.Notes}}"
+)
+
+// Slack posts the rendered text to an incoming webhook of Slack, or of a chat that accepts its payload, e.g. Mattermost.
+// See https://api.slack.com/messaging/webhooks
+type Slack struct {
+	URL string
+	// Template renders the text, DefaultSlackTemplate when empty.
+	Template string
+	Client   *http.Client
+}
+
+func (s *Slack) Execute(cmd usecase.NotifyReleaseCommand) error {
+	text, err := render(s.Template, DefaultSlackTemplate, cmd.Event)
+	if err != nil {
+		return &permanentError{err}
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return post(s.Client, s.URL, nil, body)
+}
+
+// Teams posts a message card to an incoming webhook of Microsoft Teams.
+// See https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+type Teams struct {
+	URL string
+	// Template renders the Markdown text of the card, DefaultTeamsTemplate when empty.
+	Template string
+	Client   *http.Client
+}
+
+type messageCard struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+}
+
+func (t *Teams) Execute(cmd usecase.NotifyReleaseCommand) error {
+	text, err := render(t.Template, DefaultTeamsTemplate, cmd.Event)
+	if err != nil {
+		return &permanentError{err}
+	}
+	body, err := json.Marshal(messageCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: cmd.Event.Name(),
+		Title:   cmd.Event.Name(),
+		Text:    text,
+	})
+	if err != nil {
+		return err
+	}
+	return post(t.Client, t.URL, nil, body)
+}
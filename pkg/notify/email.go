@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"msgtm/pkg/usecase"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultEmailSubject  = "{{.Name}} released"
+	DefaultEmailTemplate = "{{.Name}} was released at {{.Commit}}.\n\n{{.Notes}}"
+)
+
+// Email sends the event as a plain text mail through an SMTP server.
+// The server upgrades the connection with STARTTLS when it supports it.
+type Email struct {
+	// Addr is the host and port of the SMTP server, e.g. smtp.example.com:587.
+	Addr string
+	From string
+	To   []string
+	// Username and Password authenticate with PLAIN, anonymous when Username is empty.
+	Username string
+	Password string
+	// Subject and Template render the subject and the body, DefaultEmailSubject and DefaultEmailTemplate when empty.
+	Subject  string
+	Template string
+}
+
+func (e *Email) Execute(cmd usecase.NotifyReleaseCommand) error {
+	subject, err := render(e.Subject, DefaultEmailSubject, cmd.Event)
+	if err != nil {
+		return &permanentError{err}
+	}
+	body, err := render(e.Template, DefaultEmailTemplate, cmd.Event)
+	if err != nil {
+		return &permanentError{err}
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, err := net.SplitHostPort(e.Addr)
+		if err != nil {
+			return &permanentError{err}
+		}
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	err = smtp.SendMail(e.Addr, auth, e.From, e.To, e.message(subject, body))
+	if err != nil {
+		return fmt.Errorf("failed to send mail through %s: %w", e.Addr, err)
+	}
+	return nil
+}
+
+func (e *Email) message(subject string, body string) []byte {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "From: %s\r\n", e.From)
+	fmt.Fprintf(b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject)))
+	fmt.Fprintf(b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	// SMTP lines end with CRLF
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
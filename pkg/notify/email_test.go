@@ -0,0 +1,91 @@
+package notify_test
+
+import (
+	"msgtm/pkg/notify"
+	"msgtm/pkg/usecase"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpStandIn accepts one mail and returns its envelope and data.
+func smtpStandIn(t *testing.T) (string, chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		lines := []string{}
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				received <- lines
+				return
+			}
+			switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+			case "EHLO":
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 AUTH PLAIN")
+			case "AUTH", "MAIL", "RCPT":
+				lines = append(lines, line)
+				if command == "AUTH" {
+					text.PrintfLine("235 ok")
+				} else {
+					text.PrintfLine("250 ok")
+				}
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				data, _ := text.ReadDotLines()
+				lines = append(lines, data...)
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				received <- lines
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestEmail(t *testing.T) {
+	addr, received := smtpStandIn(t)
+	email := &notify.Email{
+		Addr:     addr,
+		From:     "msgtm@example.com",
+		To:       []string{"dev@example.com", "ops@example.com"},
+		Username: "msgtm",
+		Password: "secret",
+		Subject:  "[release] {{.Name}}",
+	}
+	err := email.Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	mail := strings.Join(<-received, "\n")
+	for _, want := range []string{
+		"AUTH PLAIN",
+		"MAIL FROM:<msgtm@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"To: dev@example.com, ops@example.com",
+		"Subject: [release] api v1.2.0",
+		"api v1.2.0 was released at 0123456789abcdef.",
+		"- add endpoint (0123456)",
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, mail)
+		}
+	}
+}
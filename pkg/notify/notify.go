@@ -0,0 +1,115 @@
+// Package notify sends release events to chat webhooks, generic webhooks and email.
+// Every sink renders the event with a text/template of its config, the fields are the ones of domain.ReleaseEvent.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var funcs = template.FuncMap{
+	// json encodes a value, e.g. {"text": {{json .Notes}}}
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// short is the first 7 characters of a commit, e.g. {{short .Commit}}
+	"short": func(id domain.CommitId) string {
+		if len(id) > 7 {
+			return string(id[:7])
+		}
+		return string(id)
+	},
+}
+
+// render executes the template text, or defaultText when text is empty.
+func render(text string, defaultText string, event *domain.ReleaseEvent) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	t, err := template.New("notification").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	b := &strings.Builder{}
+	if err := t.Execute(b, event); err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// permanentError is a failure that a retry does not fix, e.g. a rejected payload.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// post sends a JSON body, a response other than 2xx is an error.
+func post(client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	// the URL of a chat webhook is a secret, only its host is reported
+	err = fmt.Errorf("POST %s: %s: %s", req.URL.Host, res.Status, strings.TrimSpace(string(data)))
+	if res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusRequestTimeout {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// Retry sends an event again when a sink fails, waiting Delay then twice as long after every attempt.
+// Rejected payloads and broken templates are not retried.
+type Retry struct {
+	Notifier usecase.NotifyRelease
+	// Attempts is the number of times the event is sent at most, once when less than 2.
+	Attempts int
+	Delay    time.Duration
+}
+
+func (r *Retry) Execute(cmd usecase.NotifyReleaseCommand) error {
+	delay := r.Delay
+	for attempt := 1; ; attempt++ {
+		err := r.Notifier.Execute(cmd)
+		permanent := &permanentError{}
+		if err == nil || attempt >= r.Attempts || errors.As(err, &permanent) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,111 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/notify"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func event() *domain.ReleaseEvent {
+	return domain.NewReleaseEvent(domain.ReleasePushed, "origin", &domain.Release{
+		Tag:       domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 2, 0)),
+		CommitId:  "0123456789abcdef",
+		Previous:  domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 1, 0)),
+		Changelog: []domain.Commit{{Id: "0123456789abcdef", Subject: "add endpoint"}},
+	})
+}
+
+// stand-in records the bodies posted to it and answers with statuses, then 200.
+func standIn(t *testing.T, statuses ...int) (*httptest.Server, *[]string) {
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &bodies
+}
+
+func TestWebhook(t *testing.T) {
+	server, bodies := standIn(t)
+	err := (&notify.Webhook{URL: server.URL}).Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	got := map[string]any{}
+	json.Unmarshal([]byte((*bodies)[0]), &got)
+	if got["service"] != "api" || got["version"] != "v1.2.0" || got["previous_version"] != "v1.1.0" || got["remote"] != "origin" {
+		t.Errorf("body = %v, want the event of api v1.2.0", got)
+	}
+	changelog, _ := got["changelog"].([]any)
+	if len(changelog) != 1 || changelog[0].(map[string]any)["subject"] != "add endpoint" {
+		t.Errorf("changelog = %v, want add endpoint", got["changelog"])
+	}
+
+	webhook := &notify.Webhook{URL: server.URL, Template: `{"release": {{json .Name}}, "commit": "{{short .Commit}}"}`}
+	if err := webhook.Execute(usecase.NotifyReleaseCommand{Event: event()}); err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if want := `{"release": "api v1.2.0", "commit": "0123456"}`; (*bodies)[1] != want {
+		t.Errorf("body = %s, want %s", (*bodies)[1], want)
+	}
+}
+
+func TestChat(t *testing.T) {
+	server, bodies := standIn(t)
+	err := (&notify.Slack{URL: server.URL}).Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	slack := map[string]string{}
+	json.Unmarshal([]byte((*bodies)[0]), &slack)
+	if want := "*api v1.2.0* released at `0123456` (previous v1.1.0)\n• add endpoint (`0123456`)\n"; slack["text"] != want {
+		t.Errorf("slack text = %q, want %q", slack["text"], want)
+	}
+
+	err = (&notify.Teams{URL: server.URL, Template: "{{.Service}} is {{.Version}}"}).Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	teams := map[string]string{}
+	json.Unmarshal([]byte((*bodies)[1]), &teams)
+	if teams["@type"] != "MessageCard" || teams["title"] != "api v1.2.0" || teams["text"] != "api is v1.2.0" {
+		t.Errorf("teams card = %v, want a card of api v1.2.0", teams)
+	}
+
+	err = (&notify.Slack{URL: server.URL, Template: "{{.Unknown}}"}).Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err == nil {
+		t.Error("Execute() with an unknown field error = nil, want an error")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	server, bodies := standIn(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	retry := &notify.Retry{Notifier: &notify.Webhook{URL: server.URL}, Attempts: 3}
+	if err := retry.Execute(usecase.NotifyReleaseCommand{Event: event()}); err != nil {
+		t.Fatalf("Execute() error = %v, want the third attempt to succeed", err)
+	}
+	if len(*bodies) != 3 {
+		t.Errorf("sent %d times, want 3", len(*bodies))
+	}
+
+	server, bodies = standIn(t, http.StatusBadRequest)
+	retry = &notify.Retry{Notifier: &notify.Webhook{URL: server.URL + "/secret"}, Attempts: 3}
+	err := retry.Execute(usecase.NotifyReleaseCommand{Event: event()})
+	if err == nil || len(*bodies) != 1 {
+		t.Errorf("Execute() error = %v after %d attempts, want a rejection without retries", err, len(*bodies))
+	}
+	if err != nil && strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %v reveals the path of the webhook", err)
+	}
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"encoding/json"
+	"msgtm/pkg/usecase"
+	"net/http"
+)
+
+// Webhook posts the event as JSON, or the body rendered by Template.
+type Webhook struct {
+	URL string
+	// Template renders the body, the JSON of the event when empty.
+	Template string
+	Header   http.Header
+	Client   *http.Client
+}
+
+func (w *Webhook) Execute(cmd usecase.NotifyReleaseCommand) error {
+	body, err := json.Marshal(cmd.Event)
+	if err != nil {
+		return err
+	}
+	if w.Template != "" {
+		text, err := render(w.Template, "", cmd.Event)
+		if err != nil {
+			return &permanentError{err}
+		}
+		body = []byte(text)
+	}
+	return post(w.Client, w.URL, w.Header, body)
+}
@@ -0,0 +1,251 @@
+// Package output prints the results of commands, as text or tables for people and as JSON or YAML for scripts.
+// The fields of the JSON and YAML documents are a stable interface, fields are only ever added to them.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	// Text is the output of the commands before --output, e.g. tag:commit lines of list.
+	Text  Format = "text"
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+var Formats = []Format{Text, Table, JSON, YAML}
+
+// ParseFormat returns the format of a name of Formats.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+	names := make([]string, 0, len(Formats))
+	for _, format := range Formats {
+		names = append(names, string(format))
+	}
+	return "", fmt.Errorf("unknown output %s, output should be one of %s", name, strings.Join(names, ", "))
+}
+
+// Structured reports whether the output is read by scripts, so nothing else may be printed with it.
+func (f Format) Structured() bool {
+	return f == JSON || f == YAML
+}
+
+// Result is what a command did.
+type Result interface {
+	// Fail records the error the command failed with, the result holds what was done before it.
+	Fail(err error)
+	text(w io.Writer) error
+	table(w *tabwriter.Writer)
+}
+
+// Print writes result in format.
+func Print(w io.Writer, format Format, result Result) error {
+	switch format {
+	case Text:
+		return result.text(w)
+	case Table:
+		t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		result.table(t)
+		return t.Flush()
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("unknown output %s", format)
+}
+
+// failure is the error field of the results, absent when the command succeeded.
+type failure struct {
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (f *failure) Fail(err error) {
+	f.Error = err.Error()
+}
+
+// TagList is the result of list.
+type TagList struct {
+	Tags    []domain.ServiceVersion `json:"tags" yaml:"tags"`
+	failure `yaml:",inline"`
+}
+
+func NewTagList() *TagList {
+	return &TagList{Tags: []domain.ServiceVersion{}}
+}
+
+func (l *TagList) text(w io.Writer) error {
+	for _, tag := range l.Tags {
+		if _, err := fmt.Fprintf(w, "%s:%s\n", tag.Tag, tag.Commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *TagList) table(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "SERVICE\tVERSION\tTAG\tCOMMIT")
+	for _, tag := range l.Tags {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tag.Service, tag.Version, tag.Tag, ShortCommitId(string(tag.Commit)))
+	}
+}
+
+// ServiceStatus is the latest tag of a service in the repository and in the state file.
+type ServiceStatus struct {
+	Service domain.ServiceName `json:"service" yaml:"service"`
+	// Latest is the latest tag of the repository, null without a tag.
+	Latest *domain.ServiceVersion `json:"latest" yaml:"latest"`
+	// Recorded is the latest tag of the state file, null when it records none.
+	Recorded *domain.ServiceVersion `json:"recorded" yaml:"recorded"`
+	InSync   bool                   `json:"in_sync" yaml:"in_sync"`
+}
+
+// StatusList is the result of status.
+type StatusList struct {
+	Services []ServiceStatus `json:"services" yaml:"services"`
+	failure  `yaml:",inline"`
+}
+
+func NewStatusList() *StatusList {
+	return &StatusList{Services: []ServiceStatus{}}
+}
+
+func (s *StatusList) text(w io.Writer) error {
+	if s.Error != "" && len(s.Services) == 0 {
+		return nil
+	}
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	s.table(t)
+	return t.Flush()
+}
+
+func (s *StatusList) table(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "SERVICE\tLATEST\tCOMMIT\tSTATE FILE")
+	for _, status := range s.Services {
+		latest, commitId := "-", "-"
+		if status.Latest != nil {
+			latest = "v" + status.Latest.Version
+			commitId = ShortCommitId(string(status.Latest.Commit))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Service, latest, commitId, stateFileStatus(status))
+	}
+}
+
+func stateFileStatus(status ServiceStatus) string {
+	if status.InSync {
+		return "in sync"
+	}
+	if status.Recorded == nil {
+		return "not recorded"
+	}
+	return fmt.Sprintf("stale, records v%s", status.Recorded.Version)
+}
+
+type Action string
+
+const (
+	Created  Action = "created"
+	Deleted  Action = "deleted"
+	Pushed   Action = "pushed"
+	UpToDate Action = "up to date"
+	Rejected Action = "rejected"
+)
+
+// Change is a tag created, deleted or pushed by a command.
+type Change struct {
+	Action                Action `json:"action" yaml:"action"`
+	domain.ServiceVersion `yaml:",inline"`
+	// Remote is the remote of pushed tags and tags deleted on a remote, empty for local tags.
+	Remote string `json:"remote" yaml:"remote"`
+	// Reason explains why a tag was rejected.
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// ChangeList is the result of add, upgrade, push and reset.
+type ChangeList struct {
+	// DryRun reports that the changes were planned but not made.
+	DryRun  bool     `json:"dry_run" yaml:"dry_run"`
+	Changes []Change `json:"changes" yaml:"changes"`
+	failure `yaml:",inline"`
+}
+
+func NewChangeList() *ChangeList {
+	return &ChangeList{Changes: []Change{}}
+}
+
+// Add records a change of every version.
+func (c *ChangeList) Add(action Action, remote string, versions ...domain.ServiceVersion) {
+	for _, version := range versions {
+		c.Changes = append(c.Changes, Change{Action: action, ServiceVersion: version, Remote: remote})
+	}
+}
+
+// text prints a line per local change and a table of the remote ones.
+// The local changes of a dry run are left to the plan.
+func (c *ChangeList) text(w io.Writer) error {
+	remote := []Change{}
+	for _, change := range c.Changes {
+		if change.Remote != "" {
+			remote = append(remote, change)
+			continue
+		}
+		if c.DryRun {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %s at %s\n", change.Action, change.Tag, ShortCommitId(string(change.Commit))); err != nil {
+			return err
+		}
+	}
+	if len(remote) == 0 {
+		return nil
+	}
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(t, "REMOTE\tTAG\tSTATUS\tREASON")
+	for _, change := range remote {
+		// git errors span several lines, the first one is enough for the table
+		reason := strings.Split(change.Reason, "\n")[0]
+		fmt.Fprintf(t, "%s\t%s\t%s\t%s\n", change.Remote, change.Tag, change.Action, reason)
+	}
+	return t.Flush()
+}
+
+func (c *ChangeList) table(w *tabwriter.Writer) {
+	fmt.Fprintln(w, "ACTION\tREMOTE\tTAG\tCOMMIT\tREASON")
+	for _, change := range c.Changes {
+		remote := change.Remote
+		if remote == "" {
+			remote = "-"
+		}
+		reason := strings.Split(change.Reason, "\n")[0]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", change.Action, remote, change.Tag, ShortCommitId(string(change.Commit)), reason)
+	}
+}
+
+// ShortCommitId is the first 8 characters of a commit id.
+func ShortCommitId(commitId string) string {
+	if len(commitId) > 8 {
+		return commitId[:8]
+	}
+	return commitId
+}
@@ -0,0 +1,99 @@
+package output_test
+
+import (
+	"encoding/json"
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var api = domain.ServiceVersion{Service: "api", Version: "1.2.0", Tag: "api-v1.2.0", Commit: "0123456789abcdef"}
+
+func printed(t *testing.T, format output.Format, result output.Result) string {
+	b := &strings.Builder{}
+	if err := output.Print(b, format, result); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestTagList(t *testing.T) {
+	list := output.NewTagList()
+	list.Tags = append(list.Tags, api)
+	if got, want := printed(t, output.Text, list), "api-v1.2.0:0123456789abcdef\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got := printed(t, output.Table, list); !strings.Contains(got, "api      1.2.0    api-v1.2.0  01234567") {
+		t.Errorf("table = %q, want a row of api", got)
+	}
+	doc := map[string]any{}
+	if err := json.Unmarshal([]byte(printed(t, output.JSON, list)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc["error"]; ok {
+		t.Errorf("json = %v, want no error", doc)
+	}
+	tags := doc["tags"].([]any)
+	if len(tags) != 1 || tags[0].(map[string]any)["version"] != "1.2.0" {
+		t.Errorf("json tags = %v, want api 1.2.0", tags)
+	}
+}
+
+func TestChangeList(t *testing.T) {
+	changes := output.NewChangeList()
+	changes.Add(output.Created, "", api)
+	changes.Add(output.Pushed, "origin", api)
+	changes.Changes = append(changes.Changes, output.Change{Action: output.Rejected, ServiceVersion: api, Remote: "backup", Reason: "already exists\nhint: fetch first"})
+
+	text := printed(t, output.Text, changes)
+	for _, want := range []string{"created api-v1.2.0 at 01234567\n", "origin  api-v1.2.0  pushed", "backup  api-v1.2.0  rejected  already exists\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text = %q, want to contain %q", text, want)
+		}
+	}
+	changes.DryRun = true
+	if text := printed(t, output.Text, changes); strings.Contains(text, "created") {
+		t.Errorf("text of a dry run = %q, want the local changes left to the plan", text)
+	}
+
+	changes.Fail(errors.New("1 service tags were rejected"))
+	doc := struct {
+		DryRun  bool             `yaml:"dry_run"`
+		Changes []map[string]any `yaml:"changes"`
+		Error   string           `yaml:"error"`
+	}{}
+	if err := yaml.Unmarshal([]byte(printed(t, output.YAML, changes)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.DryRun || len(doc.Changes) != 3 || doc.Error != "1 service tags were rejected" {
+		t.Errorf("yaml = %+v, want a dry run of 3 changes with the error", doc)
+	}
+	if doc.Changes[1]["remote"] != "origin" || doc.Changes[1]["tag"] != "api-v1.2.0" {
+		t.Errorf("yaml changes[1] = %v, want api-v1.2.0 pushed to origin", doc.Changes[1])
+	}
+}
+
+func TestStatusList(t *testing.T) {
+	status := output.NewStatusList()
+	status.Fail(errors.New("failed to read state file"))
+	if got := printed(t, output.Text, status); got != "" {
+		t.Errorf("text of a failure = %q, want nothing", got)
+	}
+	status.Services = append(status.Services, output.ServiceStatus{Service: "api", Latest: &api})
+	if got := printed(t, output.Text, status); !strings.Contains(got, "api      v1.2.0  01234567  not recorded") {
+		t.Errorf("text = %q, want api not recorded", got)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := output.ParseFormat("yaml"); err != nil || !format.Structured() {
+		t.Errorf("ParseFormat(yaml) = %s, %v, want a structured format", format, err)
+	}
+	if _, err := output.ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) error = nil, want an error")
+	}
+}
@@ -0,0 +1,69 @@
+// Package gitea publishes service tags as Gitea or Forgejo releases through the REST API.
+package gitea
+
+import (
+	"fmt"
+	"msgtm/pkg/provider/internal/api"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Publisher creates a release per service tag, see
+// https://gitea.com/api/swagger#/repository/repoCreateRelease
+// Forgejo serves the same API.
+type Publisher struct {
+	// BaseURL is the REST API, e.g. https://gitea.example.com/api/v1.
+	BaseURL string
+	// Repository is the owner and name of the repository, e.g. octo-org/monorepo.
+	Repository string
+	Token      string
+	Client     *http.Client
+}
+
+type createRelease struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+type release struct {
+	Id int64 `json:"id"`
+}
+
+func (p *Publisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	client := p.client()
+	releases := fmt.Sprintf("%s/repos/%s/releases", strings.TrimSuffix(p.BaseURL, "/"), p.Repository)
+	created := &release{}
+	err := client.PostJSON(releases, createRelease{
+		TagName: cmd.Release.Tag.String(),
+		// only used for a tag that is not on the remote, msgtm publishes releases of pushed tags only
+		TargetCommitish: cmd.Release.CommitId.String(),
+		Name:            cmd.Release.Name(),
+		Body:            cmd.Release.Notes(),
+		Prerelease:      cmd.Release.Prerelease(),
+	}, created)
+	if err != nil {
+		return err
+	}
+	for _, asset := range cmd.Assets {
+		endpoint := fmt.Sprintf("%s/%d/assets?name=%s", releases, created.Id, url.QueryEscape(filepath.Base(asset)))
+		if err := client.PostMultipart(endpoint, "attachment", asset, nil); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", asset, err)
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) client() *api.Client {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if p.Token != "" {
+		header.Set("Authorization", "token "+p.Token)
+	}
+	return &api.Client{HTTP: p.Client, Header: header}
+}
@@ -0,0 +1,69 @@
+package gitea_test
+
+import (
+	"encoding/json"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/provider/gitea"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPublisher(t *testing.T) {
+	var created map[string]any
+	uploaded := ""
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/repos/octo-org/monorepo/releases", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"token is required"}`)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":7}`)
+	})
+	mux.HandleFunc("POST /api/v1/repos/octo-org/monorepo/releases/7/assets", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("attachment")
+		if err != nil {
+			t.Errorf("upload has no attachment: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		uploaded = r.URL.Query().Get("name") + ":" + string(data)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":1}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	asset := filepath.Join(t.TempDir(), "api.tar.gz")
+	if err := os.WriteFile(asset, []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &gitea.Publisher{BaseURL: server.URL + "/api/v1", Repository: "octo-org/monorepo", Token: "secret"}
+	release := &domain.Release{
+		Tag:      domain.NewServiceTagWithSemVer("api", domain.NewSemVer(0, 3, 0)),
+		CommitId: "0123456789abcdef",
+	}
+	err := publisher.Execute(usecase.PublishReleaseCommand{Release: release, Assets: []string{asset}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if created["tag_name"] != "api-v0.3.0" || created["target_commitish"] != "0123456789abcdef" || created["prerelease"] != true {
+		t.Errorf("release = %v, want a pre-release of api-v0.3.0", created)
+	}
+	if uploaded != "api.tar.gz:archive" {
+		t.Errorf("uploaded = %s, want api.tar.gz:archive", uploaded)
+	}
+
+	publisher.Token = ""
+	err = publisher.Execute(usecase.PublishReleaseCommand{Release: release})
+	if err == nil {
+		t.Error("Execute() without a token error = nil, want 401")
+	}
+}
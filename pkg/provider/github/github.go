@@ -0,0 +1,74 @@
+// Package github publishes service tags as GitHub releases through the REST API.
+package github
+
+import (
+	"fmt"
+	"msgtm/pkg/provider/internal/api"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Publisher creates a release per service tag, see
+// https://docs.github.com/en/rest/releases/releases#create-a-release
+type Publisher struct {
+	// BaseURL is the REST API, e.g. https://api.github.com or https://<host>/api/v3 for GitHub Enterprise.
+	BaseURL string
+	// Repository is the owner and name of the repository, e.g. octo-org/monorepo.
+	Repository string
+	Token      string
+	Client     *http.Client
+}
+
+type createRelease struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+type release struct {
+	Id        int64  `json:"id"`
+	UploadURL string `json:"upload_url"`
+	HTMLURL   string `json:"html_url"`
+}
+
+func (p *Publisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	client := p.client()
+	endpoint := fmt.Sprintf("%s/repos/%s/releases", strings.TrimSuffix(p.BaseURL, "/"), p.Repository)
+	created := &release{}
+	err := client.PostJSON(endpoint, createRelease{
+		TagName: cmd.Release.Tag.String(),
+		// only used for a tag that is not on the remote, msgtm publishes releases of pushed tags only
+		TargetCommitish: cmd.Release.CommitId.String(),
+		Name:            cmd.Release.Name(),
+		Body:            cmd.Release.Notes(),
+		Prerelease:      cmd.Release.Prerelease(),
+	}, created)
+	if err != nil {
+		return err
+	}
+	// the upload URL of a release is a URI template, e.g.
+	// https://uploads.github.com/repos/octo-org/monorepo/releases/1/assets{?name,label}
+	uploadURL, _, _ := strings.Cut(created.UploadURL, "{")
+	for _, asset := range cmd.Assets {
+		err := client.PostFile(uploadURL+"?name="+url.QueryEscape(filepath.Base(asset)), asset, nil)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", asset, err)
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) client() *api.Client {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if p.Token != "" {
+		header.Set("Authorization", "Bearer "+p.Token)
+	}
+	return &api.Client{HTTP: p.Client, Header: header}
+}
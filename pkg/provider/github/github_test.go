@@ -0,0 +1,105 @@
+package github_test
+
+import (
+	"encoding/json"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/provider/github"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeGitHub is a stand-in of the releases API recording the requests.
+type fakeGitHub struct {
+	server   *httptest.Server
+	releases []map[string]any
+	assets   map[string]string
+	auth     []string
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	f := &fakeGitHub{assets: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/octo-org/monorepo/releases", func(w http.ResponseWriter, r *http.Request) {
+		f.auth = append(f.auth, r.Header.Get("Authorization"))
+		body := map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, release := range f.releases {
+			if release["tag_name"] == body["tag_name"] {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				io.WriteString(w, `{"message":"Validation Failed","errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`)
+				return
+			}
+		}
+		f.releases = append(f.releases, body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":         1,
+			"upload_url": f.server.URL + "/api/uploads/repos/octo-org/monorepo/releases/1/assets{?name,label}",
+		})
+	})
+	mux.HandleFunc("POST /api/uploads/repos/octo-org/monorepo/releases/1/assets", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		f.assets[r.URL.Query().Get("name")] = string(data)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":1}`)
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func TestPublisher(t *testing.T) {
+	fake := newFakeGitHub(t)
+	asset := filepath.Join(t.TempDir(), "api.tar.gz")
+	if err := os.WriteFile(asset, []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &github.Publisher{
+		BaseURL:    fake.server.URL + "/api/v3/",
+		Repository: "octo-org/monorepo",
+		Token:      "secret",
+	}
+	release := &domain.Release{
+		Tag:       domain.NewServiceTagWithSemVer("api", domain.NewSemVer(0, 2, 0)),
+		CommitId:  "0123456789abcdef",
+		Changelog: []domain.Commit{{Id: "0123456789abcdef", Subject: "add endpoint"}},
+	}
+
+	err := publisher.Execute(usecase.PublishReleaseCommand{Release: release, Assets: []string{asset}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if len(fake.releases) != 1 {
+		t.Fatalf("created %d releases, want 1", len(fake.releases))
+	}
+	created := fake.releases[0]
+	want := map[string]any{
+		"tag_name":         "api-v0.2.0",
+		"target_commitish": "0123456789abcdef",
+		"name":             "api v0.2.0",
+		"body":             release.Notes(),
+		"prerelease":       true,
+	}
+	for key, value := range want {
+		if created[key] != value {
+			t.Errorf("release %s = %v, want %v", key, created[key], value)
+		}
+	}
+	if fake.auth[0] != "Bearer secret" {
+		t.Errorf("Authorization = %s, want Bearer secret", fake.auth[0])
+	}
+	if fake.assets["api.tar.gz"] != "archive" {
+		t.Errorf("assets = %v, want api.tar.gz", fake.assets)
+	}
+
+	err = publisher.Execute(usecase.PublishReleaseCommand{Release: release})
+	if err == nil || !strings.Contains(err.Error(), "tag_name already_exists") {
+		t.Errorf("Execute() of an existing release error = %v, want already_exists", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package gitlab publishes service tags as GitLab releases through the REST API.
+package gitlab
+
+import (
+	"fmt"
+	"msgtm/pkg/provider/internal/api"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Publisher creates a release per service tag, see
+// https://docs.gitlab.com/ee/api/releases/#create-a-release
+// GitLab has no pre-releases, they are published like any other release.
+type Publisher struct {
+	// BaseURL is the REST API, e.g. https://gitlab.com/api/v4.
+	BaseURL string
+	// Project is the path of the project with its namespace, e.g. group/subgroup/monorepo.
+	Project string
+	Token   string
+	Client  *http.Client
+}
+
+type createRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Ref is only used for a tag that is not on the remote, msgtm publishes releases of pushed tags only.
+	Ref string `json:"ref"`
+}
+
+type upload struct {
+	// FullPath is the path of the file on the web server,
+	// e.g. /group/monorepo/uploads/<secret>/api.tar.gz
+	FullPath string `json:"full_path"`
+}
+
+type createLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func (p *Publisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	client := p.client()
+	project := p.projectURL()
+	err := client.PostJSON(project+"/releases", createRelease{
+		TagName:     cmd.Release.Tag.String(),
+		Name:        cmd.Release.Name(),
+		Description: cmd.Release.Notes(),
+		Ref:         cmd.Release.CommitId.String(),
+	}, nil)
+	if err != nil {
+		return err
+	}
+	// assets are files uploaded to the project and linked from the release
+	links := fmt.Sprintf("%s/releases/%s/assets/links", project, url.PathEscape(cmd.Release.Tag.String()))
+	for _, asset := range cmd.Assets {
+		uploaded := &upload{}
+		if err := client.PostMultipart(project+"/uploads", "file", asset, uploaded); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", asset, err)
+		}
+		err := client.PostJSON(links, createLink{
+			Name: filepath.Base(asset),
+			URL:  p.webURL() + uploaded.FullPath,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to link %s: %w", asset, err)
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) projectURL() string {
+	return fmt.Sprintf("%s/projects/%s", strings.TrimSuffix(p.BaseURL, "/"), url.PathEscape(p.Project))
+}
+
+// webURL is the root of the web server, the API is served under /api/v4.
+func (p *Publisher) webURL() string {
+	return strings.TrimSuffix(strings.TrimSuffix(p.BaseURL, "/"), "/api/v4")
+}
+
+func (p *Publisher) client() *api.Client {
+	header := http.Header{}
+	if p.Token != "" {
+		header.Set("PRIVATE-TOKEN", p.Token)
+	}
+	return &api.Client{HTTP: p.Client, Header: header}
+}
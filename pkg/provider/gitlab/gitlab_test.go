@@ -0,0 +1,92 @@
+package gitlab_test
+
+import (
+	"encoding/json"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/provider/gitlab"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPublisher(t *testing.T) {
+	var created map[string]any
+	var link map[string]any
+	uploaded := ""
+	mux := http.NewServeMux()
+	// the project path is escaped into one path segment
+	mux.HandleFunc("POST /api/v4/projects/group%2Fmonorepo/releases", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+		if created != nil {
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, `{"message":"Release already exists"}`)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{}`)
+	})
+	mux.HandleFunc("POST /api/v4/projects/group%2Fmonorepo/uploads", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("upload has no file: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		uploaded = header.Filename + ":" + string(data)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"url":"/uploads/abc/api.tar.gz","full_path":"/group/monorepo/uploads/abc/api.tar.gz"}`)
+	})
+	mux.HandleFunc("POST /api/v4/projects/group%2Fmonorepo/releases/api-v1.0.0/assets/links", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&link)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	asset := filepath.Join(t.TempDir(), "api.tar.gz")
+	if err := os.WriteFile(asset, []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	publisher := &gitlab.Publisher{BaseURL: server.URL + "/api/v4", Project: "group/monorepo", Token: "secret"}
+	release := &domain.Release{
+		Tag:      domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 0, 0)),
+		CommitId: "0123456789abcdef",
+	}
+	err := publisher.Execute(usecase.PublishReleaseCommand{Release: release, Assets: []string{asset}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want := map[string]any{
+		"tag_name":    "api-v1.0.0",
+		"name":        "api v1.0.0",
+		"description": release.Notes(),
+		"ref":         "0123456789abcdef",
+	}
+	for key, value := range want {
+		if created[key] != value {
+			t.Errorf("release %s = %v, want %v", key, created[key], value)
+		}
+	}
+	if uploaded != "api.tar.gz:archive" {
+		t.Errorf("uploaded = %s, want api.tar.gz:archive", uploaded)
+	}
+	if link["name"] != "api.tar.gz" || link["url"] != server.URL+"/group/monorepo/uploads/abc/api.tar.gz" {
+		t.Errorf("link = %v, want the uploaded file", link)
+	}
+
+	err = publisher.Execute(usecase.PublishReleaseCommand{Release: release})
+	if err == nil || !strings.Contains(err.Error(), "Release already exists") {
+		t.Errorf("Execute() of an existing release error = %v, want Release already exists", err)
+	}
+}
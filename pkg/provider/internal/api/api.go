@@ -0,0 +1,147 @@
+// Package api sends the JSON requests of the release APIs of hosting services.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Client struct {
+	HTTP *http.Client
+	// Header is added to every request, e.g. the token.
+	Header http.Header
+}
+
+// PostJSON posts body as JSON and decodes the response into result unless it is nil.
+func (c *Client) PostJSON(url string, body any, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.Do(req, result)
+}
+
+// PostFile posts the content of the file as the request body.
+func (c *Client) PostFile(url string, fileName string, result any) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return c.Do(req, result)
+}
+
+// PostMultipart posts the file as the field of a multipart form.
+func (c *Client) PostMultipart(url string, field string, fileName string, result any) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile(field, filepath.Base(fileName))
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return c.Do(req, result)
+}
+
+// Do sends the request, a response outside of 2xx is an error with the message of its body.
+func (c *Client) Do(req *http.Request, result any) error {
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return responseError(req, res, data)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(data, result)
+}
+
+// responseError reports the message of an error response, the services differ in the fields, e.g.
+// GitHub {"message": "Validation Failed", "errors": [{"code": "already_exists", "field": "tag_name"}]},
+// GitLab {"message": "Release already exists"} or {"error": "tag_name is missing"}.
+func responseError(req *http.Request, res *http.Response, data []byte) error {
+	body := struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+		Errors  []struct {
+			Code  string `json:"code"`
+			Field string `json:"field"`
+		} `json:"errors"`
+	}{}
+	prefix := fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, res.Status)
+	if json.Unmarshal(data, &body) != nil {
+		return fmt.Errorf("%s", prefix)
+	}
+	message := body.Error
+	if len(body.Message) > 0 {
+		// the message is an object of the invalid fields for some GitLab errors
+		if json.Unmarshal(body.Message, &message) != nil {
+			message = string(body.Message)
+		}
+	}
+	if message == "" {
+		return fmt.Errorf("%s", prefix)
+	}
+	details := []string{}
+	for _, e := range body.Errors {
+		if e.Code != "" {
+			details = append(details, strings.TrimSpace(e.Field+" "+e.Code))
+		}
+	}
+	if len(details) > 0 {
+		return fmt.Errorf("%s: %s (%s)", prefix, message, strings.Join(details, ", "))
+	}
+	return fmt.Errorf("%s: %s", prefix, message)
+}
@@ -0,0 +1,100 @@
+// Package provider selects the hosting service releases of service tags are published on.
+package provider
+
+import (
+	"fmt"
+	"msgtm/pkg/provider/gitea"
+	"msgtm/pkg/provider/github"
+	"msgtm/pkg/provider/gitlab"
+	"msgtm/pkg/usecase"
+	"os"
+)
+
+// Provider publishes the releases of service tags on a hosting service,
+// it is the PublishRelease executor of the service.
+type Provider interface {
+	Execute(usecase.PublishReleaseCommand) error
+}
+
+type Kind string
+
+const (
+	GitHub Kind = "github"
+	GitLab Kind = "gitlab"
+	// Gitea also covers Forgejo, which serves the same API.
+	Gitea Kind = "gitea"
+)
+
+// Kinds are the supported hosting services.
+var Kinds = []Kind{GitHub, GitLab, Gitea}
+
+// Target is a repository on a hosting service.
+type Target struct {
+	Kind Kind
+	// BaseURL is the REST API, see New for the default.
+	BaseURL string
+	// Repository is the path of the repository, e.g. octo-org/monorepo, see New for the default.
+	Repository string
+	Token      string
+}
+
+// defaults of a hosting service, the environment variables are set by its CI.
+type defaults struct {
+	baseURLEnv    string
+	baseURL       string
+	repositoryEnv string
+	tokenEnv      string
+}
+
+var services = map[Kind]defaults{
+	GitHub: {baseURLEnv: "GITHUB_API_URL", baseURL: "https://api.github.com", repositoryEnv: "GITHUB_REPOSITORY", tokenEnv: "GITHUB_TOKEN"},
+	GitLab: {baseURLEnv: "CI_API_V4_URL", baseURL: "https://gitlab.com/api/v4", repositoryEnv: "CI_PROJECT_PATH", tokenEnv: "GITLAB_TOKEN"},
+	// self-hosted only, the base URL and the repository must be configured
+	Gitea: {tokenEnv: "GITEA_TOKEN"},
+}
+
+// DefaultTokenEnv returns the environment variable of the token of a hosting service.
+func DefaultTokenEnv(kind Kind) string {
+	return services[kind].tokenEnv
+}
+
+// New returns the provider of the target. An empty BaseURL or Repository is read from
+// the environment of the CI of the hosting service, the public service is the default base URL.
+func New(target Target) (Provider, error) {
+	service, ok := services[target.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %s, provider should be one of %v", target.Kind, Kinds)
+	}
+	baseURL := firstNonEmpty(target.BaseURL, env(service.baseURLEnv), service.baseURL)
+	if baseURL == "" {
+		return nil, fmt.Errorf("the base URL of %s must be configured", target.Kind)
+	}
+	repository := firstNonEmpty(target.Repository, env(service.repositoryEnv))
+	if repository == "" {
+		return nil, fmt.Errorf("the repository of %s must be configured", target.Kind)
+	}
+	switch target.Kind {
+	case GitLab:
+		return &gitlab.Publisher{BaseURL: baseURL, Project: repository, Token: target.Token}, nil
+	case Gitea:
+		return &gitea.Publisher{BaseURL: baseURL, Repository: repository, Token: target.Token}, nil
+	default:
+		return &github.Publisher{BaseURL: baseURL, Repository: repository, Token: target.Token}, nil
+	}
+}
+
+func env(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
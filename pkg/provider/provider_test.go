@@ -0,0 +1,54 @@
+package provider_test
+
+import (
+	"msgtm/pkg/provider"
+	"msgtm/pkg/provider/gitea"
+	"msgtm/pkg/provider/github"
+	"msgtm/pkg/provider/gitlab"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITHUB_API_URL", "")
+	t.Setenv("GITHUB_REPOSITORY", "octo-org/from-env")
+	t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+	t.Setenv("CI_PROJECT_PATH", "")
+	tests := []struct {
+		target provider.Target
+		want   provider.Provider
+	}{
+		{
+			provider.Target{Kind: provider.GitHub, Token: "t"},
+			&github.Publisher{BaseURL: "https://api.github.com", Repository: "octo-org/from-env", Token: "t"},
+		},
+		{
+			provider.Target{Kind: provider.GitLab, Repository: "group/monorepo"},
+			&gitlab.Publisher{BaseURL: "https://gitlab.example.com/api/v4", Project: "group/monorepo"},
+		},
+		{
+			provider.Target{Kind: provider.Gitea, BaseURL: "https://gitea.example.com/api/v1", Repository: "org/monorepo"},
+			&gitea.Publisher{BaseURL: "https://gitea.example.com/api/v1", Repository: "org/monorepo"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := provider.New(tt.target)
+		if err != nil {
+			t.Fatalf("New(%+v) error = %v, want nil", tt.target, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("New(%+v) = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+
+	invalid := []provider.Target{
+		{Kind: "bitbucket", Repository: "org/monorepo"},
+		{Kind: provider.GitLab},
+		{Kind: provider.Gitea, Repository: "org/monorepo"},
+	}
+	for _, target := range invalid {
+		if _, err := provider.New(target); err == nil {
+			t.Errorf("New(%+v) error = nil, want an error", target)
+		}
+	}
+}
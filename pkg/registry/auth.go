@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Credentials returns the username and password of a registry host, ok is false when there are none.
+type Credentials func(host string) (username string, password string, ok bool)
+
+// EnvCredentials reads the credentials of every registry from environment variables.
+func EnvCredentials(usernameEnv string, passwordEnv string) Credentials {
+	return func(host string) (string, string, bool) {
+		username, password := os.Getenv(usernameEnv), os.Getenv(passwordEnv)
+		return username, password, username != "" || password != ""
+	}
+}
+
+// DockerConfigCredentials reads the auths of $DOCKER_CONFIG/config.json or ~/.docker/config.json,
+// which docker login writes. Credential helpers are not supported.
+func DockerConfigCredentials() Credentials {
+	return func(host string) (string, string, bool) {
+		dir := os.Getenv("DOCKER_CONFIG")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", false
+			}
+			dir = filepath.Join(home, ".docker")
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+		if err != nil {
+			return "", "", false
+		}
+		config := struct {
+			Auths map[string]struct {
+				Auth string `json:"auth"`
+			} `json:"auths"`
+		}{}
+		if json.Unmarshal(data, &config) != nil {
+			return "", "", false
+		}
+		key := host
+		if host == dockerHub {
+			key = "https://index.docker.io/v1/"
+		}
+		auth, ok := config.Auths[key]
+		if !ok {
+			return "", "", false
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", false
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		return username, password, ok
+	}
+}
+
+// FirstCredentials returns the credentials of the first source that has some for the host.
+func FirstCredentials(sources ...Credentials) Credentials {
+	return func(host string) (string, string, bool) {
+		for _, source := range sources {
+			if username, password, ok := source(host); ok {
+				return username, password, true
+			}
+		}
+		return "", "", false
+	}
+}
+
+// do sends the request and authenticates as the registry asks for it on 401.
+// newRequest builds the request again for the authenticated retry.
+func (c *Client) do(ref reference, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		if authorization, ok := c.authorizations[ref.String()]; ok {
+			req.Header.Set("Authorization", authorization)
+		}
+		client := c.HTTP
+		if client == nil {
+			client = http.DefaultClient
+		}
+		return client.Do(req)
+	}
+	res, err := send()
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	res.Body.Close()
+	if _, retried := c.authorizations[ref.String()]; retried {
+		return nil, fmt.Errorf("%s: %s", ref, res.Status)
+	}
+	authorization, err := c.login(ref, res.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate to %s: %w", ref.host, err)
+	}
+	if c.authorizations == nil {
+		c.authorizations = map[string]string{}
+	}
+	c.authorizations[ref.String()] = authorization
+	return send()
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// login returns the Authorization header answering the challenge, e.g.
+// Bearer realm="https://auth.example.com/token",service="registry.example.com"
+func (c *Client) login(ref reference, challenge string) (string, error) {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+	for _, match := range challengeParam.FindAllStringSubmatch(rest, -1) {
+		params[match[1]] = match[2]
+	}
+	username, password, hasCredentials := "", "", false
+	if c.Credentials != nil {
+		username, password, hasCredentials = c.Credentials(ref.host)
+	}
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCredentials {
+			return "", errors.New("no credentials")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+		return c.token(ref, params, username, password, hasCredentials)
+	default:
+		return "", fmt.Errorf("unsupported authentication %q", challenge)
+	}
+}
+
+// token gets a token that can pull and push the repository from the token server of the registry, see
+// https://distribution.github.io/distribution/spec/auth/token/
+func (c *Client) token(ref reference, params map[string]string, username string, password string, hasCredentials bool) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push", ref.name))
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if hasCredentials {
+		req.SetBasicAuth(username, password)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	data, err := readResponse(res, "GET "+realm.Host+realm.Path)
+	if err != nil {
+		return "", err
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", err
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	if body.Token == "" {
+		return "", errors.New("no token in the response of the token server")
+	}
+	return "Bearer " + body.Token, nil
+}
@@ -0,0 +1,173 @@
+// Package registry copies image tags through the OCI distribution API, without a docker daemon.
+// See https://github.com/opencontainers/distribution-spec/blob/main/spec.md
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"strings"
+)
+
+// manifestTypes are the manifests an image tag can point at, an index covers several platforms.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Client talks to the registries of images.
+type Client struct {
+	HTTP *http.Client
+	// PlainHTTP talks to the registries over HTTP instead of HTTPS, e.g. to a local registry.
+	PlainHTTP bool
+	// Credentials of the registries, anonymous when nil.
+	Credentials Credentials
+	// authorizations are the Authorization headers per repository.
+	authorizations map[string]string
+}
+
+// reference is an image repository of a registry.
+type reference struct {
+	host string
+	name string
+}
+
+// dockerHub is the registry of images without a registry host, e.g. alpine.
+const dockerHub = "registry-1.docker.io"
+
+func parseRepository(repository string) reference {
+	host, name, ok := strings.Cut(repository, "/")
+	if ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return reference{host: host, name: name}
+	}
+	if !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return reference{host: dockerHub, name: repository}
+}
+
+func (r reference) String() string {
+	return r.host + "/" + r.name
+}
+
+func (c *Client) manifestURL(ref reference, tag string) string {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, ref.host, ref.name, tag)
+}
+
+// Exists reports whether the tag of the image is in the registry.
+func (c *Client) Exists(image domain.Image) (bool, error) {
+	ref := parseRepository(image.Repository)
+	res, err := c.do(ref, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodHead, c.manifestURL(ref, image.Tag), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+		return req, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return false, nil
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return true, nil
+	default:
+		return false, fmt.Errorf("HEAD %s: %s", image, res.Status)
+	}
+}
+
+// Copy puts the manifest of source as the tag of target, the blobs are shared in the repository.
+func (c *Client) Copy(source domain.Image, target domain.Image) error {
+	ref := parseRepository(source.Repository)
+	if parseRepository(target.Repository) != ref {
+		return fmt.Errorf("can not copy %s to another repository %s", source, target)
+	}
+	res, err := c.do(ref, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, c.manifestURL(ref, source.Tag), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest, err := readResponse(res, "GET "+source.String())
+	if err != nil {
+		return err
+	}
+	// the manifest is put as is, so that the digest of the target is the one of the source
+	mediaType := res.Header.Get("Content-Type")
+	res, err = c.do(ref, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPut, c.manifestURL(ref, target.Tag), bytes.NewReader(manifest))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", mediaType)
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = readResponse(res, "PUT "+target.String())
+	return err
+}
+
+// readResponse reads the body of a successful response, or the errors of the registry, e.g.
+// {"errors": [{"code": "MANIFEST_UNKNOWN", "message": "manifest unknown"}]}
+func readResponse(res *http.Response, request string) ([]byte, error) {
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return data, nil
+	}
+	body := struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	messages := []string{}
+	if json.Unmarshal(data, &body) == nil {
+		for _, e := range body.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", e.Code, e.Message))
+		}
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("%s: %s", request, res.Status)
+	}
+	return nil, fmt.Errorf("%s: %s: %s", request, res.Status, strings.Join(messages, ", "))
+}
+
+type ImageChecker struct {
+	Client *Client
+}
+
+func (i *ImageChecker) Execute(query usecase.ImageExistsQuery) (bool, error) {
+	return i.Client.Exists(query.Image)
+}
+
+type ImageCopier struct {
+	Client *Client
+}
+
+func (i *ImageCopier) Execute(cmd usecase.CopyImageCommand) error {
+	return i.Client.Copy(cmd.Source, cmd.Target)
+}
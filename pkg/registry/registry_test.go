@@ -0,0 +1,122 @@
+package registry_test
+
+import (
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/registry"
+	"msgtm/pkg/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const indexType = "application/vnd.oci.image.index.v1+json"
+
+// fakeRegistry serves the manifests of one repository behind token authentication.
+type fakeRegistry struct {
+	server    *httptest.Server
+	manifests map[string]string
+	types     map[string]string
+	scopes    []string
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	f := &fakeRegistry{
+		manifests: map[string]string{"0123456789abcdef": `{"manifests":[]}`},
+		types:     map[string]string{"0123456789abcdef": indexType},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "ci" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.scopes = append(f.scopes, r.URL.Query().Get("service")+" "+r.URL.Query().Get("scope"))
+		io.WriteString(w, `{"token":"t0ken"}`)
+	})
+	mux.HandleFunc("/v2/platform/api/manifests/{reference}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, f.server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reference := r.PathValue("reference")
+		switch r.Method {
+		case http.MethodHead, http.MethodGet:
+			manifest, ok := f.manifests[reference]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`)
+				return
+			}
+			w.Header().Set("Content-Type", f.types[reference])
+			if r.Method == http.MethodGet {
+				io.WriteString(w, manifest)
+			}
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			f.manifests[reference] = string(data)
+			f.types[reference] = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func TestCopy(t *testing.T) {
+	fake := newFakeRegistry(t)
+	client := &registry.Client{
+		PlainHTTP: true,
+		Credentials: func(host string) (string, string, bool) {
+			return "ci", "secret", true
+		},
+	}
+	repository := strings.TrimPrefix(fake.server.URL, "http://") + "/platform/api"
+	source := domain.Image{Repository: repository, Tag: "0123456789abcdef"}
+	target := domain.Image{Repository: repository, Tag: "v1.2.3"}
+
+	checker := &registry.ImageChecker{Client: client}
+	for image, want := range map[domain.Image]bool{source: true, target: false} {
+		got, err := checker.Execute(usecase.ImageExistsQuery{Image: image})
+		if err != nil {
+			t.Fatalf("Execute(%s) error = %v, want nil", image, err)
+		}
+		if got != want {
+			t.Errorf("Execute(%s) = %v, want %v", image, got, want)
+		}
+	}
+
+	copier := &registry.ImageCopier{Client: client}
+	if err := copier.Execute(usecase.CopyImageCommand{Source: source, Target: target}); err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if fake.manifests["v1.2.3"] != `{"manifests":[]}` || fake.types["v1.2.3"] != indexType {
+		t.Errorf("copied manifest = %s %s, want the index of the source", fake.types["v1.2.3"], fake.manifests["v1.2.3"])
+	}
+	// the token of the repository is reused
+	if len(fake.scopes) != 1 || fake.scopes[0] != "fake repository:platform/api:pull,push" {
+		t.Errorf("token scopes = %v, want one token for platform/api", fake.scopes)
+	}
+
+	missing := domain.Image{Repository: repository, Tag: "fedcba"}
+	err := copier.Execute(usecase.CopyImageCommand{Source: missing, Target: target})
+	if err == nil || !strings.Contains(err.Error(), "MANIFEST_UNKNOWN") {
+		t.Errorf("Execute() of a missing image error = %v, want MANIFEST_UNKNOWN", err)
+	}
+}
+
+func TestCopyWithoutCredentials(t *testing.T) {
+	fake := newFakeRegistry(t)
+	client := &registry.Client{PlainHTTP: true}
+	repository := strings.TrimPrefix(fake.server.URL, "http://") + "/platform/api"
+
+	_, err := client.Exists(domain.Image{Repository: repository, Tag: "0123456789abcdef"})
+	if err == nil || !strings.Contains(err.Error(), "failed to authenticate") {
+		t.Errorf("Exists() error = %v, want an authentication failure", err)
+	}
+}
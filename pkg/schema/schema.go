@@ -0,0 +1,160 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema that msgtm needs to describe its files.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+}
+
+// Types is a JSON Schema "type" keyword.
+// It is marshaled as a plain string when it holds a single type.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t Types) allows(typ string) bool {
+	for _, allowed := range t {
+		if allowed == typ {
+			return true
+		}
+		// every integer is also a number
+		if allowed == "number" && typ == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+)
+
+// FromType generates a schema from a Go type.
+// Property names are taken from the yaml tag (falling back to the json tag),
+// and constraints from the jsonschema tag, e.g.
+//
+//	Version string `yaml:"version" jsonschema:"required,pattern=^v?[0-9]+$"`
+//
+// Supported constraints are required, pattern=, enum=a|b and description=.
+func FromType(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := FromType(t.Elem())
+		s.Type = append(s.Type, TypeNull)
+		return s
+	case reflect.Struct:
+		s := &Schema{
+			Type:                 Types{TypeObject},
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := fieldName(field)
+			if name == "-" {
+				continue
+			}
+			property := FromType(field.Type)
+			if applyTag(property, field.Tag.Get("jsonschema")) {
+				s.Required = append(s.Required, name)
+			}
+			s.Properties[name] = property
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return &Schema{
+			Type:  Types{TypeArray},
+			Items: FromType(t.Elem()),
+		}
+	case reflect.Map:
+		return &Schema{
+			Type:                 Types{TypeObject},
+			AdditionalProperties: FromType(t.Elem()),
+		}
+	case reflect.String:
+		return &Schema{Type: Types{TypeString}}
+	case reflect.Bool:
+		return &Schema{Type: Types{TypeBoolean}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{TypeInteger}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{TypeNumber}}
+	}
+	// interface and other dynamic types accept anything
+	return &Schema{}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"yaml", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name != "" {
+			return name
+		}
+	}
+	return strings.ToLower(field.Name)
+}
+
+// applyTag applies the jsonschema tag to s and reports whether the field is required.
+func applyTag(s *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "required":
+			required = true
+		case "pattern":
+			s.Pattern = value
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		case "description":
+			s.Description = value
+		}
+	}
+	return required
+}
+
+// Document returns a copy of s that can be published as a standalone schema.
+func Document(s *Schema, title string) *Schema {
+	doc := *s
+	doc.Schema = Draft
+	doc.Title = title
+	return &doc
+}
@@ -0,0 +1,202 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError points at the place in the document that violates the schema.
+type ValidationError struct {
+	File    string
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, path, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, path, e.Message)
+}
+
+// ValidationErrors is returned by Validate when the document does not conform to the schema.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks a YAML (or JSON) document against the schema.
+// It returns ValidationErrors with the line and column of every violation,
+// or a plain error when the document can not be parsed at all.
+func Validate(s *Schema, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	// an empty document is treated as null
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: 1, Column: 1}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	errs := ValidationErrors{}
+	validateNode(s, node, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateFile is Validate for a document read from fileName.
+// The file name is included in every ValidationError.
+func ValidateFile(s *Schema, fileName string, data []byte) error {
+	err := Validate(s, data)
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs {
+			e.File = fileName
+		}
+		return errs
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+	return nil
+}
+
+func validateNode(s *Schema, node *yaml.Node, path string, errs *ValidationErrors) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	report := func(format string, args ...any) {
+		*errs = append(*errs, &ValidationError{
+			Path:    path,
+			Line:    node.Line,
+			Column:  node.Column,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	typ := nodeType(node)
+	if len(s.Type) > 0 && !s.Type.allows(typ) {
+		expected := strings.Join(s.Type, " or ")
+		if s.Pattern != "" {
+			expected = fmt.Sprintf("%s matching %s", expected, s.Pattern)
+		}
+		got := typ
+		if node.Kind == yaml.ScalarNode && typ != TypeNull {
+			got = fmt.Sprintf("%s %s", typ, node.Value)
+		}
+		report("expected %s, got %s", expected, got)
+		return
+	}
+	switch typ {
+	case TypeObject:
+		validateObject(s, node, path, errs, report)
+	case TypeArray:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range node.Content {
+			validateNode(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case TypeString:
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				report("invalid pattern in schema %s: %s", s.Pattern, err.Error())
+				return
+			}
+			if !re.MatchString(node.Value) {
+				report("%q does not match pattern %s", node.Value, s.Pattern)
+			}
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+			report("%q is not one of %s", node.Value, strings.Join(s.Enum, ", "))
+		}
+	}
+}
+
+func validateObject(s *Schema, node *yaml.Node, path string, errs *ValidationErrors, report func(string, ...any)) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		seen[key.Value] = true
+		childPath := joinPath(path, key.Value)
+		if property, ok := s.Properties[key.Value]; ok {
+			validateNode(property, value, childPath, errs)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, &ValidationError{
+					Path:    childPath,
+					Line:    key.Line,
+					Column:  key.Column,
+					Message: fmt.Sprintf("unknown field %q", key.Value),
+				})
+			}
+		case *Schema:
+			validateNode(additional, value, childPath, errs)
+		}
+	}
+	missing := []string{}
+	for _, name := range s.Required {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		report("missing required field %q", name)
+	}
+}
+
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return TypeObject
+	case yaml.SequenceNode:
+		return TypeArray
+	}
+	switch node.ShortTag() {
+	case "!!null":
+		return TypeNull
+	case "!!bool":
+		return TypeBoolean
+	case "!!int":
+		return TypeInteger
+	case "!!float":
+		return TypeNumber
+	}
+	return TypeString
+}
+
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
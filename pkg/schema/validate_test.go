@@ -0,0 +1,107 @@
+package schema_test
+
+import (
+	"errors"
+	"msgtm/pkg/schema"
+	"reflect"
+	"testing"
+)
+
+type testDocument struct {
+	Services []struct {
+		Name    string  `yaml:"name" jsonschema:"required,pattern=^[a-z]+$"`
+		Version *string `yaml:"version"`
+		Kind    string  `yaml:"kind" jsonschema:"enum=app|job"`
+	} `yaml:"services" jsonschema:"required"`
+}
+
+func TestValidate(t *testing.T) {
+	s := schema.FromType(reflect.TypeOf(testDocument{}))
+	tests := []struct {
+		name string
+		data string
+		want []schema.ValidationError
+	}{
+		{
+			name: "valid",
+			data: `
+services:
+  - name: api
+    version: v1.0.0
+    kind: app
+  - name: web
+    version: null`,
+			want: nil,
+		},
+		{
+			name: "wrong type is reported with its position",
+			data: `
+services:
+  - name: api
+    version: 1.2`,
+			want: []schema.ValidationError{
+				{Path: "services[0].version", Line: 4, Column: 14, Message: "expected string or null, got number 1.2"},
+			},
+		},
+		{
+			name: "pattern, enum and unknown field",
+			data: `
+services:
+  - name: API
+    kind: cron
+    foo: bar`,
+			want: []schema.ValidationError{
+				{Path: "services[0].name", Line: 3, Column: 11, Message: `"API" does not match pattern ^[a-z]+$`},
+				{Path: "services[0].kind", Line: 4, Column: 11, Message: `"cron" is not one of app, job`},
+				{Path: "services[0].foo", Line: 5, Column: 5, Message: `unknown field "foo"`},
+			},
+		},
+		{
+			name: "missing required field",
+			data: `
+services:
+  - version: v1.0.0`,
+			want: []schema.ValidationError{
+				{Path: "services[0]", Line: 3, Column: 5, Message: `missing required field "name"`},
+			},
+		},
+		{
+			name: "empty document",
+			data: ``,
+			want: []schema.ValidationError{
+				{Path: "", Line: 1, Column: 1, Message: "expected object, got null"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(s, []byte(tt.data))
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var errs schema.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+			got := []schema.ValidationError{}
+			for _, e := range errs {
+				got = append(got, *e)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	s := schema.FromType(reflect.TypeOf(testDocument{}))
+	err := schema.ValidateFile(s, "services.yaml", []byte("services:\n  - name: 1\n"))
+	want := "services.yaml:2:11: services[0].name: expected string matching ^[a-z]+$, got integer 1"
+	if err == nil || err.Error() != want {
+		t.Errorf("ValidateFile() error = %v, want %s", err, want)
+	}
+}
@@ -3,8 +3,8 @@ package subcmd
 import (
 	"fmt"
 	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
 	"msgtm/pkg/usecase"
-	"os"
 )
 
 type TagAddCommandParameter struct {
@@ -12,10 +12,21 @@ type TagAddCommandParameter struct {
 	CommitId       string
 	Services       []string
 	FromConfigFile string
+	usecase.VersionPolicy
+	PushParameter
+	PublishParameter
 }
 
-func TagAddCommand(register usecase.RegisterServiceTags) SubCommand[TagAddCommandParameter] {
+func TagAddCommand(register usecase.RegisterServiceTags, pusher usecase.CommitPusher, destroyer usecase.DestroyServiceTags, refs usecase.ListTagRefs, finder usecase.CommitFinder, commits usecase.ListCommits, publishers Publishers, notifier usecase.NotifyRelease, result *output.ChangeList) SubCommand[TagAddCommandParameter] {
 	return func(param TagAddCommandParameter) error {
+		if err := param.requirePush(param.PushParameter); err != nil {
+			return err
+		}
+		recorder := &usecase.RecordingRegister{Register: register}
+		publisher, err := param.publisher(publishers, param.remote())
+		if err != nil {
+			return err
+		}
 		semVer, err := domain.FromStr(param.Version)
 		if err != nil {
 			return fmt.Errorf("failed to parse version: %w", err)
@@ -23,11 +34,10 @@ func TagAddCommand(register usecase.RegisterServiceTags) SubCommand[TagAddComman
 		serviceNames := []domain.ServiceName{}
 		if param.FromConfigFile != "" {
 			// read from config file
-			file, err := os.Open(param.FromConfigFile)
+			state, err := ReadStateFile(param.FromConfigFile)
 			if err != nil {
 				return fmt.Errorf("failed to read config file: %w", err)
 			}
-			state, err := domain.FromReader(file, domain.YAML)
 			for _, service := range state.ServiceTagStates {
 				serviceNames = append(serviceNames, *service.ServiceName)
 			}
@@ -41,8 +51,27 @@ func TagAddCommand(register usecase.RegisterServiceTags) SubCommand[TagAddComman
 		if param.CommitId != "" {
 			commitId = domain.CommitId(param.CommitId)
 		}
+		tags := make([]*domain.ServiceTagWithSemVer, 0, len(serviceNames))
+		for _, serviceName := range serviceNames {
+			tags = append(tags, domain.NewServiceTagWithSemVer(serviceName, semVer))
+		}
+		tagged, err := usecase.CheckNewVersions(tags, &commitId, param.VersionPolicy, refs, finder)
+		if err != nil {
+			return err
+		}
+		if len(tagged) > 0 {
+			versions, err := usecase.ServiceVersionsOf(tagged, refs)
+			if err != nil {
+				return err
+			}
+			result.Add(output.UpToDate, "", versions...)
+			serviceNames = untaggedServices(serviceNames, tagged)
+		}
+		if len(serviceNames) == 0 {
+			return nil
+		}
 		err = usecase.CreateServiceTags(
-			register,
+			recorder,
 			&commitId,
 			serviceNames,
 			semVer,
@@ -50,6 +79,33 @@ func TagAddCommand(register usecase.RegisterServiceTags) SubCommand[TagAddComman
 		if err != nil {
 			return fmt.Errorf("failed to create service tags: %w", err)
 		}
-		return nil
+		created, err := recordCreated(result, refs, recorder.Registered)
+		if err != nil {
+			return err
+		}
+		err = param.push(pusher, destroyer, recorder.Registered, result, created)
+		if err != nil {
+			return err
+		}
+		err = param.publish(publisher, refs, commits, recorder.Registered)
+		if err != nil {
+			return err
+		}
+		return notify(notifier, domain.ReleaseAdded, param.Push, refs, commits, recorder.Registered)
+	}
+}
+
+// untaggedServices returns the services without one of the tags.
+func untaggedServices(services []domain.ServiceName, tags []*domain.ServiceTagWithSemVer) []domain.ServiceName {
+	tagged := map[domain.ServiceName]bool{}
+	for _, tag := range tags {
+		tagged[tag.Service] = true
+	}
+	untagged := []domain.ServiceName{}
+	for _, service := range services {
+		if !tagged[service] {
+			untagged = append(untagged, service)
+		}
 	}
+	return untagged
 }
@@ -0,0 +1,63 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+)
+
+type CIOutputCommandParameter struct {
+	// Services limits the versions to these services, every service with a tag by default.
+	Services []string
+	// Remote reads the versions from the tags of the remote instead of the local tags when not empty.
+	Remote string
+}
+
+// CIOutputCommand hands the latest version of every service to the following jobs of the pipeline.
+func CIOutputCommand(refs usecase.ListTagRefs, remoteList usecase.ListRemoteTagRefs, writer usecase.WriteVersions) SubCommand[CIOutputCommandParameter] {
+	return func(param CIOutputCommandParameter) error {
+		if param.Remote != "" {
+			remote := domain.RemoteAddr(param.Remote)
+			refs = usecase.RemoteTagRefs(remoteList, &remote)
+		}
+		services := []domain.ServiceName{}
+		for _, service := range param.Services {
+			services = append(services, domain.ServiceName(service))
+		}
+		versions, err := usecase.LatestServiceVersions(services, refs)
+		if err != nil {
+			return fmt.Errorf("failed to list service tags: %w", err)
+		}
+		for _, service := range services {
+			if !hasVersion(versions, service) {
+				return fmt.Errorf("%s has no service tag", service)
+			}
+		}
+		return writer.Execute(usecase.WriteVersionsCommand{Versions: versions})
+	}
+}
+
+func hasVersion(versions []domain.ServiceVersion, service domain.ServiceName) bool {
+	for _, version := range versions {
+		if version.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// writeVersions hands the versions of the created tags to the pipeline, nothing is written when writer is nil.
+func writeVersions(writer usecase.WriteVersions, refs usecase.ListTagRefs, tags []*domain.ServiceTagWithSemVer) error {
+	if writer == nil {
+		return nil
+	}
+	versions, err := usecase.ServiceVersionsOf(tags, refs)
+	if err != nil {
+		return err
+	}
+	err = writer.Execute(usecase.WriteVersionsCommand{Versions: versions})
+	if err != nil {
+		return fmt.Errorf("failed to write CI outputs: %w", err)
+	}
+	return nil
+}
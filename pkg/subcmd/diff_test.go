@@ -0,0 +1,22 @@
+package subcmd
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "services:\n- name: api\n  latest: null\n  prev: null\n"
+	after := "services:\n- name: api\n  latest:\n    version: v1.0.0\n  prev: null\n"
+	want := "--- state.yaml\n+++ state.yaml\n" +
+		"@@ -1,4 +1,5 @@\n" +
+		" services:\n" +
+		" - name: api\n" +
+		"-  latest: null\n" +
+		"+  latest:\n" +
+		"+    version: v1.0.0\n" +
+		"   prev: null\n"
+	if got := UnifiedDiff("state.yaml", before, after); got != want {
+		t.Errorf("UnifiedDiff() = %q, want %q", got, want)
+	}
+	if got := UnifiedDiff("state.yaml", before, before); got != "" {
+		t.Errorf("UnifiedDiff() of the same text = %q, want empty", got)
+	}
+}
@@ -0,0 +1,44 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+)
+
+type EnvCommandParameter struct {
+	// Services limits the versions to these services, every service with a version by default.
+	Services []string
+	// StateFile reads the versions from the state file instead of the tags when not empty.
+	StateFile string
+}
+
+// EnvCommand prints the latest version and commit of every service for builds.
+func EnvCommand(refs usecase.ListTagRefs, writer usecase.WriteVersions) SubCommand[EnvCommandParameter] {
+	return func(param EnvCommandParameter) error {
+		services := []domain.ServiceName{}
+		for _, service := range param.Services {
+			services = append(services, domain.ServiceName(service))
+		}
+		var versions []domain.ServiceVersion
+		if param.StateFile != "" {
+			state, err := ReadStateFile(param.StateFile)
+			if err != nil {
+				return err
+			}
+			versions = usecase.StateServiceVersions(state, services)
+		} else {
+			var err error
+			versions, err = usecase.LatestServiceVersions(services, refs)
+			if err != nil {
+				return fmt.Errorf("failed to list service tags: %w", err)
+			}
+		}
+		for _, service := range services {
+			if !hasVersion(versions, service) {
+				return fmt.Errorf("%s has no version", service)
+			}
+		}
+		return writer.Execute(usecase.WriteVersionsCommand{Versions: versions})
+	}
+}
@@ -0,0 +1,127 @@
+package subcmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PrePushHook   = "pre-push"
+	PostMergeHook = "post-merge"
+	// hookMarker identifies the hooks written by msgtm, so that they can be overwritten safely.
+	hookMarker = "# installed by msgtm hooks install"
+)
+
+// hookScript calls back into msgtm, so that the rules of the hook live in Go code.
+func hookScript(executable string, args string) string {
+	return fmt.Sprintf("#!/bin/sh\n%s\nexec %q %s\n", hookMarker, executable, args)
+}
+
+type HooksInstallCommandParameter struct {
+	HooksDir string
+	// Executable is the msgtm binary the hooks call.
+	Executable string
+	PostMerge  bool
+	// Force overwrites hooks that were not written by msgtm.
+	Force bool
+}
+
+func HooksInstallCommand() SubCommand[HooksInstallCommandParameter] {
+	return func(param HooksInstallCommandParameter) error {
+		hooks := map[string]string{
+			PrePushHook: hookScript(param.Executable, `hooks pre-push "$@"`),
+		}
+		if param.PostMerge {
+			hooks[PostMergeHook] = hookScript(param.Executable, "sync")
+		}
+		if err := os.MkdirAll(param.HooksDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create hooks directory: %w", err)
+		}
+		for _, name := range []string{PrePushHook, PostMergeHook} {
+			script, ok := hooks[name]
+			if !ok {
+				continue
+			}
+			path := filepath.Join(param.HooksDir, name)
+			current, err := os.ReadFile(path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err == nil && !strings.Contains(string(current), hookMarker) && !param.Force {
+				return fmt.Errorf("%s already exists and was not installed by msgtm, use --force to overwrite it", path)
+			}
+			if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("installed %s\n", path)
+		}
+		return nil
+	}
+}
+
+type PrePushCommandParameter struct {
+	// Remote is the first argument git passes to the pre-push hook.
+	Remote string
+	// Refs are the lines git writes to the standard input of the pre-push hook.
+	Refs     io.Reader
+	Services []domain.ServiceName
+	// ProtectedBranch is checked locally and as the remote-tracking branch of Remote.
+	ProtectedBranch string
+}
+
+func PrePushCommand(remoteList usecase.ListRemoteTagRefs, finder usecase.CommitFinder, ancestor usecase.IsAncestor) SubCommand[PrePushCommandParameter] {
+	return func(param PrePushCommandParameter) error {
+		tags, err := pushedTags(param.Refs)
+		if err != nil {
+			return err
+		}
+		remote := domain.RemoteAddr(param.Remote)
+		rules := usecase.PrePushRules{
+			Services: param.Services,
+			ProtectedBranches: []string{
+				param.ProtectedBranch,
+				fmt.Sprintf("%s/%s", param.Remote, param.ProtectedBranch),
+			},
+		}
+		violations, err := usecase.ValidatePushedTags(tags, rules, remoteList, finder, ancestor, &remote)
+		if err != nil {
+			return fmt.Errorf("failed to validate pushed tags: %w", err)
+		}
+		if len(violations) == 0 {
+			return nil
+		}
+		messages := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			messages = append(messages, "  "+violation.String())
+		}
+		return fmt.Errorf("msgtm rejected the push of service tags:\n%s", strings.Join(messages, "\n"))
+	}
+}
+
+// pushedTags reads the tags created or updated by a push from the pre-push input,
+// "<local ref> <local sha> <remote ref> <remote sha>" per line. Deleted tags are skipped.
+func pushedTags(r io.Reader) ([]domain.GitTag, error) {
+	tags := []domain.GitTag{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		localRef, localSha := fields[0], fields[1]
+		tag, ok := strings.CutPrefix(localRef, "refs/tags/")
+		if !ok || strings.Trim(localSha, "0") == "" {
+			continue
+		}
+		tags = append(tags, domain.GitTag(tag))
+	}
+	return tags, scanner.Err()
+}
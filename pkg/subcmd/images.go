@@ -0,0 +1,47 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+)
+
+type ImagesRetagCommandParameter struct {
+	CommitId string
+	// Services limits the images to these services, the service tags of the commit by default.
+	Services []string
+	Naming   *domain.ImageNaming
+}
+
+// ImagesRetagCommand tags the images built for a commit with the versions of its service tags.
+func ImagesRetagCommand(getter usecase.CommitTagGetter, finder usecase.CommitFinder, exists usecase.ImageExists, copier usecase.CopyImage) SubCommand[ImagesRetagCommandParameter] {
+	return func(param ImagesRetagCommandParameter) error {
+		revision := domain.GitTag(domain.HEAD)
+		if param.CommitId != "" {
+			revision = domain.GitTag(param.CommitId)
+		}
+		// the images are tagged with the full commit id, not with HEAD
+		commitId, err := finder.Execute(usecase.FindCommitQuery{Tag: &revision})
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", revision, err)
+		}
+		gitTags, err := getter.Execute(usecase.GetCommitTagQuery{CommitId: commitId})
+		if err != nil {
+			return fmt.Errorf("failed to get the tags of %s: %w", commitId.String(), err)
+		}
+		tags := []*domain.ServiceTagWithSemVer{}
+		for _, tag := range *domain.FilterServiceTags(gitTags) {
+			if len(param.Services) == 0 || contains(param.Services, tag.Service.String()) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			return fmt.Errorf("no service tags at %s", revision)
+		}
+		retags, err := usecase.RetagServiceImages(tags, *commitId, param.Naming, exists, copier)
+		for _, retag := range retags {
+			fmt.Printf("%s -> %s\n", retag.Source, retag.Target.Tag)
+		}
+		return err
+	}
+}
@@ -3,35 +3,38 @@ package subcmd
 import (
 	"fmt"
 	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
 	"msgtm/pkg/usecase"
 )
 
 type ServiceTagsListParameter struct {
 	Filter []string
 	IsAll  bool
+	// Remote lists the tags of the remote instead of the local tags when not empty.
+	Remote string
 }
 
-func ServiceTagsListCommand(list usecase.ListTags, finder usecase.CommitFinder) SubCommand[ServiceTagsListParameter] {
+func ServiceTagsListCommand(list usecase.ListTagRefs, remoteList usecase.ListRemoteTagRefs, result *output.TagList) SubCommand[ServiceTagsListParameter] {
 	return func(param ServiceTagsListParameter) error {
-		f := func(s *domain.ServiceName) bool {
+		if param.Remote != "" {
+			remote := domain.RemoteAddr(param.Remote)
+			list = usecase.RemoteTagRefs(remoteList, &remote)
+		}
+		services := []domain.ServiceName{}
+		if !param.IsAll {
 			for _, filter := range param.Filter {
-				if filter == s.String() {
-					return true
-				}
+				services = append(services, domain.ServiceName(filter))
 			}
-			return false
-		}
-		if param.IsAll {
-			f = func(s *domain.ServiceName) bool {
-				return true
+			if len(services) == 0 {
+				return nil
 			}
 		}
-		infos, err := usecase.ServiceTagsList(f, list, finder)
+		infos, err := usecase.ServiceTagsList(services, list)
 		if err != nil {
 			return fmt.Errorf("failed to list service tags: %w", err)
 		}
 		for _, info := range infos {
-			fmt.Printf("%s:%s\n", info.Tag, info.CommitId)
+			result.Tags = append(result.Tags, domain.NewServiceVersion(info.Tag, *info.CommitId))
 		}
 		return nil
 	}
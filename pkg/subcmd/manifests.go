@@ -0,0 +1,120 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/manifest"
+	"msgtm/pkg/usecase"
+	"os"
+)
+
+// ManifestMapping is a value of a YAML file set to the latest version of a service.
+type ManifestMapping struct {
+	Service domain.ServiceName
+	File    string
+	// Path is the YAML path of the value, Image is used when empty.
+	Path string
+	// Image is the image whose tag is set in kustomization images and image keys.
+	Image string
+	// Value is the template of the value, see domain.ImageTagFields. It is domain.DefaultImageTargetTag when empty.
+	Value string
+}
+
+type ManifestsUpdateCommandParameter struct {
+	Mappings []ManifestMapping
+	// Services limits the update to these services, every service with mappings by default.
+	Services []string
+	// Check fails when a manifest is not up to date instead of writing it.
+	Check bool
+}
+
+// ManifestsUpdateCommand sets the values of the manifests to the latest version of their services.
+func ManifestsUpdateCommand(refs usecase.ListTagRefs, writer usecase.WriteFile) SubCommand[ManifestsUpdateCommandParameter] {
+	return func(param ManifestsUpdateCommandParameter) error {
+		mappings := []ManifestMapping{}
+		services := []domain.ServiceName{}
+		for _, mapping := range param.Mappings {
+			if len(param.Services) > 0 && !contains(param.Services, string(mapping.Service)) {
+				continue
+			}
+			if mapping.Path == "" && mapping.Image == "" {
+				return fmt.Errorf("the manifest %s of %s has neither a path nor an image", mapping.File, mapping.Service)
+			}
+			mappings = append(mappings, mapping)
+			services = append(services, mapping.Service)
+		}
+		if len(mappings) == 0 {
+			return fmt.Errorf("no manifests are configured")
+		}
+		versions, err := usecase.LatestServiceVersions(services, refs)
+		if err != nil {
+			return fmt.Errorf("failed to list service tags: %w", err)
+		}
+		latest := map[domain.ServiceName]domain.ServiceVersion{}
+		for _, version := range versions {
+			latest[version.Service] = version
+		}
+
+		files := []string{}
+		originals := map[string][]byte{}
+		contents := map[string][]byte{}
+		outdated := 0
+		for _, mapping := range mappings {
+			version, ok := latest[mapping.Service]
+			if !ok {
+				return fmt.Errorf("%s has no service tag", mapping.Service)
+			}
+			tag, err := version.Tag.ToServiceTag()
+			if err != nil {
+				return err
+			}
+			text := mapping.Value
+			if text == "" {
+				text = domain.DefaultImageTargetTag
+			}
+			value, err := domain.RenderTag(text, tag, version.Commit)
+			if err != nil {
+				return err
+			}
+			data, ok := contents[mapping.File]
+			if !ok {
+				data, err = os.ReadFile(mapping.File)
+				if err != nil {
+					return err
+				}
+				files = append(files, mapping.File)
+				originals[mapping.File] = data
+			}
+			var changes []manifest.Change
+			if mapping.Path != "" {
+				data, changes, err = manifest.SetPath(data, mapping.Path, value)
+			} else {
+				data, changes, err = manifest.SetImageTag(data, mapping.Image, value)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update %s of %s: %w", mapping.File, mapping.Service, err)
+			}
+			contents[mapping.File] = data
+			for _, change := range changes {
+				fmt.Printf("%s:%d: %s: %s -> %s\n", mapping.File, change.Line, change.Where, change.Old, change.New)
+			}
+			outdated += len(changes)
+		}
+		if outdated == 0 {
+			fmt.Println("manifests are up to date")
+			return nil
+		}
+		if param.Check {
+			return fmt.Errorf("%d values of manifests are not the latest versions, run msgtm manifests update", outdated)
+		}
+		for _, file := range files {
+			if string(originals[file]) == string(contents[file]) {
+				continue
+			}
+			if err := writer.Execute(usecase.WriteFileCommand{Name: file, Data: contents[file]}); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// manifestsFixture writes a values file shared by the image tags of api and web, api is outdated.
+func manifestsFixture(t *testing.T) (string, []ManifestMapping) {
+	t.Helper()
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	data := "api:\n  image:\n    tag: v1.0.0\nweb:\n  image:\n    tag: web-abc\n"
+	if err := os.WriteFile(values, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return values, []ManifestMapping{
+		{Service: "api", File: values, Path: "api.image.tag"},
+		{Service: "web", File: values, Path: "web.image.tag", Value: "{{.Service}}-{{.ShortCommit}}"},
+	}
+}
+
+var manifestRefs = stubTagRefs{
+	{Tag: "api-v1.0.0", CommitId: "0000001"},
+	{Tag: "api-v1.1.0", CommitId: "0000002"},
+	{Tag: "web-v2.0.0", CommitId: "abc0000"},
+}
+
+func TestManifestsUpdateCommandWritesSharedFileOnce(t *testing.T) {
+	values, mappings := manifestsFixture(t)
+	writer := memoryWriter{}
+
+	err := ManifestsUpdateCommand(manifestRefs, writer)(ManifestsUpdateCommandParameter{Mappings: mappings})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want := memoryWriter{values: "api:\n  image:\n    tag: v1.1.0\nweb:\n  image:\n    tag: web-abc0000\n"}
+	if !reflect.DeepEqual(writer, want) {
+		t.Errorf("written = %q, want %q", writer, want)
+	}
+}
+
+func TestManifestsUpdateCommandCheckWritesNothing(t *testing.T) {
+	values, mappings := manifestsFixture(t)
+	writer := memoryWriter{}
+
+	err := ManifestsUpdateCommand(manifestRefs, writer)(ManifestsUpdateCommandParameter{Mappings: mappings, Check: true})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want an error for the outdated values")
+	}
+	if len(writer) > 0 {
+		t.Errorf("written = %q, want nothing", writer)
+	}
+	data, err := os.ReadFile(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "api:\n  image:\n    tag: v1.0.0\nweb:\n  image:\n    tag: web-abc\n" {
+		t.Errorf("values.yaml = %q, want it unchanged", data)
+	}
+
+	upToDate := stubTagRefs{{Tag: "api-v1.0.0", CommitId: "0000001"}, {Tag: "web-v2.0.0", CommitId: "abc"}}
+	err = ManifestsUpdateCommand(upToDate, writer)(ManifestsUpdateCommandParameter{Mappings: mappings, Check: true})
+	if err != nil {
+		t.Errorf("Execute() of up to date manifests error = %v, want nil", err)
+	}
+}
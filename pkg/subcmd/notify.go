@@ -0,0 +1,21 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+)
+
+// notify sends a release event of every tag, nothing is sent when notifier is nil.
+// remote is the remote the tags were pushed to, local tags are not released yet
+// and are notified when they are pushed, so nothing is sent when it is empty.
+func notify(notifier usecase.NotifyRelease, action domain.ReleaseAction, remote string, refs usecase.ListTagRefs, commits usecase.ListCommits, tags []*domain.ServiceTagWithSemVer) error {
+	if notifier == nil || remote == "" || len(tags) == 0 {
+		return nil
+	}
+	err := usecase.NotifyReleases(notifier, action, remote, tags, refs, commits)
+	if err != nil {
+		return fmt.Errorf("failed to send release notifications: %w", err)
+	}
+	return nil
+}
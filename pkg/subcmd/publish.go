@@ -0,0 +1,150 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"path/filepath"
+	"strings"
+)
+
+// Publishers returns the release publisher of the hosting service of a remote.
+type Publishers func(remote string) (usecase.PublishRelease, error)
+
+// PublishParameter publishes releases of the tags created or pushed by add, upgrade and push.
+type PublishParameter struct {
+	Publish bool
+}
+
+// publisher returns the publisher of the remote, nil when nothing is published.
+// It is resolved before any tag is created, so that a wrong config fails early.
+func (p PublishParameter) publisher(publishers Publishers, remote string) (usecase.PublishRelease, error) {
+	if !p.Publish {
+		return nil, nil
+	}
+	publisher, err := publishers(remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure releases of %s: %w", remote, err)
+	}
+	return publisher, nil
+}
+
+// requirePush refuses to publish releases of tags that are only created locally.
+// The hosting services would create lightweight tags of their own for the releases,
+// and pushing the annotated tags of msgtm later would be rejected by the remote.
+func (p PublishParameter) requirePush(push PushParameter) error {
+	if p.Publish && push.Push == "" {
+		return fmt.Errorf("publishing releases needs --push, the tags must be on the remote first")
+	}
+	return nil
+}
+
+func (p PublishParameter) publish(publisher usecase.PublishRelease, refs usecase.ListTagRefs, commits usecase.ListCommits, tags []*domain.ServiceTagWithSemVer) error {
+	if publisher == nil || len(tags) == 0 {
+		return nil
+	}
+	err := usecase.PublishReleases(publisher, tags, refs, commits)
+	if err != nil {
+		return fmt.Errorf("failed to publish releases: %w", err)
+	}
+	return nil
+}
+
+// WithAssets uploads the files matching the glob patterns to every release.
+// A pattern matching nothing is an error.
+func WithAssets(publisher usecase.PublishRelease, patterns []string) (usecase.PublishRelease, error) {
+	assets := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no asset matches %s", pattern)
+		}
+		assets = append(assets, matches...)
+	}
+	return &assetsPublisher{publisher: publisher, assets: assets}, nil
+}
+
+type assetsPublisher struct {
+	publisher usecase.PublishRelease
+	assets    []string
+}
+
+func (a *assetsPublisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	cmd.Assets = append(cmd.Assets, a.assets...)
+	return a.publisher.Execute(cmd)
+}
+
+type PublishCommandParameter struct {
+	CommitId string
+	// Services limits the releases to these services, the service tags of the commit by default.
+	Services []string
+	// Remote selects the hosting service of the releases.
+	Remote string
+}
+
+// PublishCommand publishes a release of every service tag of a commit.
+// The tags must have been pushed to the remote, the hosting services would create tags of their own otherwise.
+func PublishCommand(getter usecase.CommitTagGetter, refs usecase.ListTagRefs, remoteList usecase.ListRemoteTagRefs, commits usecase.ListCommits, publishers Publishers) SubCommand[PublishCommandParameter] {
+	return func(param PublishCommandParameter) error {
+		commitId := domain.HEAD
+		if param.CommitId != "" {
+			commitId = domain.CommitId(param.CommitId)
+		}
+		remote := param.Remote
+		if remote == "" {
+			remote = string(domain.Origin)
+		}
+		publishing := PublishParameter{Publish: true}
+		publisher, err := publishing.publisher(publishers, remote)
+		if err != nil {
+			return err
+		}
+		gitTags, err := getter.Execute(usecase.GetCommitTagQuery{CommitId: &commitId})
+		if err != nil {
+			return fmt.Errorf("failed to get the tags of %s: %w", commitId.String(), err)
+		}
+		tags := []*domain.ServiceTagWithSemVer{}
+		for _, tag := range *domain.FilterServiceTags(gitTags) {
+			if len(param.Services) == 0 || contains(param.Services, tag.Service.String()) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			return fmt.Errorf("no service tags at %s", commitId.String())
+		}
+		if err := requirePushed(remoteList, remote, tags); err != nil {
+			return err
+		}
+		return publishing.publish(publisher, refs, commits, tags)
+	}
+}
+
+// requirePushed returns an error when some of the tags are not on the remote.
+func requirePushed(remoteList usecase.ListRemoteTagRefs, remote string, tags []*domain.ServiceTagWithSemVer) error {
+	services := []domain.ServiceName{}
+	for _, tag := range tags {
+		services = append(services, tag.Service)
+	}
+	addr := domain.RemoteAddr(remote)
+	refs, err := remoteList.Execute(usecase.ListRemoteTagRefsQuery{RemoteAddr: &addr, Services: services})
+	if err != nil {
+		return fmt.Errorf("failed to list the tags of %s: %w", remote, err)
+	}
+	pushed := map[domain.GitTag]bool{}
+	for _, ref := range *refs {
+		pushed[ref.Tag] = true
+	}
+	missing := []string{}
+	for _, tag := range tags {
+		if !pushed[tag.ToGitTag()] {
+			missing = append(missing, tag.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s not on %s, push the tags before publishing their releases", strings.Join(missing, ", "), remote)
+	}
+	return nil
+}
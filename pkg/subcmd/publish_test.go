@@ -0,0 +1,74 @@
+package subcmd
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type stubRemoteRefs []domain.TagRef
+
+func (s stubRemoteRefs) Execute(usecase.ListRemoteTagRefsQuery) (*[]domain.TagRef, error) {
+	refs := []domain.TagRef(s)
+	return &refs, nil
+}
+
+type stubCommits []domain.Commit
+
+func (s stubCommits) Execute(usecase.ListCommitsQuery) (*[]domain.Commit, error) {
+	commits := []domain.Commit(s)
+	return &commits, nil
+}
+
+type recordingPublisher struct {
+	published []domain.GitTag
+}
+
+func (r *recordingPublisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	r.published = append(r.published, cmd.Release.Tag.ToGitTag())
+	return nil
+}
+
+func TestPublishCommandPublishesOnlyPushedTags(t *testing.T) {
+	tags := stubTagGetter{"api-v1.0.0"}
+	refs := stubTagRefs{{Tag: "api-v1.0.0", CommitId: "abc"}}
+	tests := []struct {
+		name    string
+		remote  stubRemoteRefs
+		wantErr bool
+	}{
+		{name: "pushed", remote: stubRemoteRefs{{Tag: "api-v1.0.0", CommitId: "abc"}}},
+		{name: "not pushed", remote: stubRemoteRefs{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			publishers := func(string) (usecase.PublishRelease, error) { return publisher, nil }
+			err := PublishCommand(tags, refs, tt.remote, stubCommits{}, publishers)(PublishCommandParameter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if published := len(publisher.published) > 0; published == tt.wantErr {
+				t.Errorf("published = %v, want %v", publisher.published, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagAddCommandRefusesToPublishWithoutPush(t *testing.T) {
+	register := &stubRegister{}
+	publishers := func(string) (usecase.PublishRelease, error) { return &recordingPublisher{}, nil }
+	param := TagAddCommandParameter{
+		Version:          "v1.0.0",
+		Services:         []string{"api"},
+		PublishParameter: PublishParameter{Publish: true},
+	}
+	err := TagAddCommand(register, nil, nil, stubTagRefs{}, stubFinder("abc"), stubCommits{}, publishers, nil, &output.ChangeList{})(param)
+	if err == nil {
+		t.Fatal("err = nil, want an error for --publish without --push")
+	}
+	if len(register.registered) > 0 {
+		t.Errorf("registered = %v, want nothing", register.registered)
+	}
+}
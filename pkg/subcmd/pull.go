@@ -0,0 +1,57 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+)
+
+type PullCommandParameter struct {
+	Remote   string
+	Services []string
+	Prefer   string
+}
+
+func PullCommand(local usecase.ListTagRefs, remoteList usecase.ListRemoteTagRefs, fetcher usecase.FetchTags) SubCommand[PullCommandParameter] {
+	return func(param PullCommandParameter) error {
+		strategy, err := usecase.ParseConflictStrategy(param.Prefer)
+		if err != nil {
+			return err
+		}
+		remote := domain.Origin
+		if param.Remote != "" {
+			remote = domain.RemoteAddr(param.Remote)
+		}
+		services := []domain.ServiceName{}
+		for _, service := range param.Services {
+			services = append(services, domain.ServiceName(service))
+		}
+
+		result, err := usecase.PullServiceTags(local, remoteList, fetcher, &remote, services, strategy)
+		if result != nil {
+			printPullResult(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to pull service tags: %w", err)
+		}
+		return nil
+	}
+}
+
+func printPullResult(result *usecase.PullResult) {
+	for _, ref := range result.Fetched {
+		fmt.Printf("%-12s %s:%s\n", "fetched", ref.Tag, ref.CommitId)
+	}
+	for _, ref := range result.UpToDate {
+		fmt.Printf("%-12s %s:%s\n", "up to date", ref.Tag, ref.CommitId)
+	}
+	for _, conflict := range result.Overwritten {
+		fmt.Printf("%-12s %s:%s (was %s)\n", "overwritten", conflict.Tag, conflict.Remote, conflict.Local)
+	}
+	for _, conflict := range result.Kept {
+		fmt.Printf("%-12s %s:%s (remote %s)\n", "kept local", conflict.Tag, conflict.Local, conflict.Remote)
+	}
+	for _, conflict := range result.Unresolved {
+		fmt.Printf("%-12s %s local %s, remote %s\n", "conflict", conflict.Tag, conflict.Local, conflict.Remote)
+	}
+}
@@ -3,34 +3,144 @@ package subcmd
 import (
 	"fmt"
 	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
 	"msgtm/pkg/usecase"
 )
 
 type PushCommandParameter struct {
 	CommitId string
-	Remote   string
+	Remotes  []string
+	// Atomic pushes all of the tags to a remote or none of them.
+	Atomic bool
+	PublishParameter
 }
 
-func PushCommand(getter usecase.CommitTagGetter, pusher usecase.CommitPusher) SubCommand[PushCommandParameter] {
+func PushCommand(getter usecase.CommitTagGetter, local usecase.ListTagRefs, remoteList usecase.ListRemoteTagRefs, pusher usecase.CommitPusher, commits usecase.ListCommits, publishers Publishers, notifier usecase.NotifyRelease, result *output.ChangeList) SubCommand[PushCommandParameter] {
 	return func(param PushCommandParameter) error {
 		commitId := domain.HEAD
 		if param.CommitId != "" {
 			commitId = domain.CommitId(param.CommitId)
 		}
-		remote := domain.Origin
-		if param.Remote != "" {
-			remote = domain.RemoteAddr(param.Remote)
+		remotes := []*domain.RemoteAddr{}
+		for _, remote := range param.Remotes {
+			remote := domain.RemoteAddr(remote)
+			remotes = append(remotes, &remote)
+		}
+		if len(remotes) == 0 {
+			remote := domain.Origin
+			remotes = append(remotes, &remote)
+		}
+		releasePublishers := map[domain.RemoteAddr]usecase.PublishRelease{}
+		for _, remote := range remotes {
+			publisher, err := param.publisher(publishers, remote.String())
+			if err != nil {
+				return err
+			}
+			releasePublishers[*remote] = publisher
 		}
 
-		err := usecase.PushAll(
+		report, err := usecase.PushAllToRemotes(
 			getter,
+			local,
+			remoteList,
 			pusher,
-			&remote,
+			remotes,
 			&commitId,
+			param.Atomic,
 		)
+		if report != nil {
+			if err := recordPushReport(result, local, report); err != nil {
+				return err
+			}
+		}
 		if err != nil {
 			return fmt.Errorf("failed to push service tags: %w", err)
 		}
+		for _, remote := range remotes {
+			pushed := newlyPushedTags(report, *remote)
+			err := param.publish(releasePublishers[*remote], local, commits, pushed)
+			if err != nil {
+				return err
+			}
+			err = notify(notifier, domain.ReleasePushed, remote.String(), local, commits, pushed)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// newlyPushedTags are the tags of the report pushed to the remote, tags already there are not released again.
+func newlyPushedTags(report *usecase.PushReport, remote domain.RemoteAddr) []*domain.ServiceTagWithSemVer {
+	tags := []*domain.ServiceTagWithSemVer{}
+	for _, outcome := range report.Outcomes {
+		if outcome.Remote == remote && outcome.Status == usecase.Pushed {
+			tags = append(tags, outcome.Tag)
+		}
+	}
+	return tags
+}
+
+// recordPushReport adds the outcome of every tag on every remote to result.
+func recordPushReport(result *output.ChangeList, local usecase.ListTagRefs, report *usecase.PushReport) error {
+	tags := make([]*domain.ServiceTagWithSemVer, 0, len(report.Outcomes))
+	for _, outcome := range report.Outcomes {
+		tags = append(tags, outcome.Tag)
+	}
+	versions, err := usecase.ServiceVersionsOf(tags, local)
+	if err != nil {
+		return err
+	}
+	for i, outcome := range report.Outcomes {
+		result.Changes = append(result.Changes, output.Change{
+			Action:         output.Action(outcome.Status),
+			ServiceVersion: versions[i],
+			Remote:         outcome.Remote.String(),
+			Reason:         outcome.Reason,
+		})
+	}
+	return nil
+}
+
+// PushParameter pushes the tags created by add and upgrade.
+type PushParameter struct {
+	// Push is the remote to push the created tags to, nothing is pushed when empty.
+	Push string
+	// Atomic pushes all of the created tags or none of them.
+	Atomic bool
+	// Rollback deletes the created tags locally when the push fails.
+	Rollback bool
+}
+
+// remote is the remote the created tags are pushed to, and whose hosting service releases are published on.
+func (p PushParameter) remote() string {
+	if p.Push == "" {
+		return string(domain.Origin)
+	}
+	return p.Push
+}
+
+// push pushes the created tags and adds them to result as pushed.
+func (p PushParameter) push(pusher usecase.CommitPusher, destroyer usecase.DestroyServiceTags, tags []*domain.ServiceTagWithSemVer, result *output.ChangeList, created []domain.ServiceVersion) error {
+	if p.Push == "" {
 		return nil
 	}
+	remote := domain.RemoteAddr(p.Push)
+	err := usecase.PushRegisteredTags(pusher, destroyer, &remote, tags, p.Atomic, p.Rollback)
+	if err != nil {
+		return fmt.Errorf("failed to push service tags: %w", err)
+	}
+	result.Add(output.Pushed, p.Push, created...)
+	return nil
+}
+
+// recordCreated adds the created tags to result with their commits.
+func recordCreated(result *output.ChangeList, refs usecase.ListTagRefs, tags []*domain.ServiceTagWithSemVer) ([]domain.ServiceVersion, error) {
+	versions, err := usecase.ServiceVersionsOf(tags, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the created service tags: %w", err)
+	}
+	result.Add(output.Created, "", versions...)
+	return versions, nil
 }
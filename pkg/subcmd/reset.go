@@ -3,6 +3,7 @@ package subcmd
 import (
 	"fmt"
 	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
 	"msgtm/pkg/usecase"
 )
 
@@ -20,29 +21,96 @@ func (d *DestroyDecorator) Execute(cmd usecase.DestroyServiceTagsCommand) error
 	return nil
 }
 
+// recordingDestroyer adds the tags deleted by Destroyer to Result, with their commits before the deletion.
+type recordingDestroyer struct {
+	Destroyer usecase.DestroyServiceTags
+	// Remote is the remote the tags are deleted on, empty for local tags.
+	Remote string
+	Refs   usecase.ListTagRefs
+	Result *output.ChangeList
+}
+
+func (r *recordingDestroyer) Execute(cmd usecase.DestroyServiceTagsCommand) error {
+	versions, err := usecase.ServiceVersionsOf(*cmd.Tags, r.Refs)
+	if err != nil {
+		return err
+	}
+	if err := r.Destroyer.Execute(cmd); err != nil {
+		return err
+	}
+	r.Result.Add(output.Deleted, r.Remote, versions...)
+	return nil
+}
+
 type ResetCommandParameter struct {
 	Origin       bool
 	ExcludeLocal bool
 	CommitId     string
+	Policy       usecase.ProtectionPolicy
+	// Force is required to delete tags on the remote.
+	Force bool
+	// Yes deletes tags on the remote without asking for confirmation.
+	Yes bool
+	// DryRun deletes nothing, so there is nothing to confirm.
+	DryRun bool
+}
+
+// Confirm asks the user a yes or no question.
+type Confirm func(question string) (bool, error)
+
+// confirmingDestroyer deletes the tags through Destroyer once Confirm accepts Question.
+// It runs after the protection checks, so the user is never asked about tags that can not be deleted.
+type confirmingDestroyer struct {
+	Destroyer usecase.DestroyServiceTags
+	Confirm   Confirm
+	Question  string
 }
 
-func ResetCommand(getter usecase.CommitTagGetter, local usecase.DestroyServiceTags, remote usecase.DestroyServiceTags) SubCommand[ResetCommandParameter] {
+func (c *confirmingDestroyer) Execute(cmd usecase.DestroyServiceTagsCommand) error {
+	if len(*cmd.Tags) > 0 {
+		ok, err := c.Confirm(c.Question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("reset was cancelled")
+		}
+	}
+	return c.Destroyer.Execute(cmd)
+}
+
+func ResetCommand(getter usecase.CommitTagGetter, local usecase.DestroyServiceTags, remote usecase.DestroyServiceTags, refs usecase.ListTagRefs, confirm Confirm, result *output.ChangeList) SubCommand[ResetCommandParameter] {
 	return func(param ResetCommandParameter) error {
 		commitId := domain.HEAD
 		if param.CommitId != "" {
 			commitId = domain.CommitId(param.CommitId)
 		}
+		if param.Origin && !param.Force {
+			return fmt.Errorf("deleting tags on %s requires --force", domain.Origin)
+		}
 
 		destroyer := &DestroyDecorator{}
 		if param.Origin {
-			destroyer.Clients = append(destroyer.Clients, remote)
+			destroyer.Clients = append(destroyer.Clients, &recordingDestroyer{Destroyer: remote, Remote: string(domain.Origin), Refs: refs, Result: result})
 		}
 		if !param.ExcludeLocal {
-			destroyer.Clients = append(destroyer.Clients, local)
+			destroyer.Clients = append(destroyer.Clients, &recordingDestroyer{Destroyer: local, Refs: refs, Result: result})
+		}
+		var confirmed usecase.DestroyServiceTags = destroyer
+		if param.Origin && !param.Yes && !param.DryRun {
+			confirmed = &confirmingDestroyer{
+				Destroyer: destroyer,
+				Confirm:   confirm,
+				Question:  fmt.Sprintf("Delete the service tags of %s on %s?", commitId.String(), domain.Origin),
+			}
 		}
 
 		err := usecase.ResetServiceTags(
-			destroyer,
+			&usecase.ProtectedDestroyer{
+				Destroyer: confirmed,
+				Policy:    param.Policy,
+				List:      refs,
+			},
 			getter,
 			&commitId,
 		)
@@ -0,0 +1,87 @@
+package subcmd
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type stubTagGetter []domain.GitTag
+
+func (s stubTagGetter) Execute(usecase.GetCommitTagQuery) (*[]domain.GitTag, error) {
+	tags := []domain.GitTag(s)
+	return &tags, nil
+}
+
+type stubTagRefs []domain.TagRef
+
+func (s stubTagRefs) Execute(usecase.ListTagRefsQuery) (*[]domain.TagRef, error) {
+	refs := []domain.TagRef(s)
+	return &refs, nil
+}
+
+type countingDestroyer struct {
+	calls int
+}
+
+func (c *countingDestroyer) Execute(usecase.DestroyServiceTagsCommand) error {
+	c.calls++
+	return nil
+}
+
+func TestResetCommandConfirmsRemoteDeletion(t *testing.T) {
+	tags := stubTagGetter{"api-v1.0.0"}
+	refs := stubTagRefs{{Tag: "api-v1.0.0", CommitId: "abc"}}
+	tests := []struct {
+		name    string
+		param   ResetCommandParameter
+		wantErr bool
+		asked   bool
+		deleted bool
+	}{
+		{
+			name:    "confirmed",
+			param:   ResetCommandParameter{Origin: true, Force: true},
+			asked:   true,
+			deleted: true,
+		},
+		{
+			name:    "protected tags are refused before asking",
+			param:   ResetCommandParameter{Origin: true, Force: true, Policy: usecase.ProtectionPolicy{Services: []domain.ServiceName{"api"}}},
+			wantErr: true,
+		},
+		{
+			name:    "dry run does not ask",
+			param:   ResetCommandParameter{Origin: true, Force: true, DryRun: true},
+			deleted: true,
+		},
+		{
+			name:    "yes does not ask",
+			param:   ResetCommandParameter{Origin: true, Force: true, Yes: true},
+			deleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := false
+			confirm := func(string) (bool, error) {
+				asked = true
+				return true, nil
+			}
+			local, remote := &countingDestroyer{}, &countingDestroyer{}
+			err := ResetCommand(tags, local, remote, refs, confirm, &output.ChangeList{})(tt.param)
+			var protected *usecase.ProtectedTagsError
+			if tt.wantErr != errors.As(err, &protected) {
+				t.Fatalf("err = %v, want protected tags error %v", err, tt.wantErr)
+			}
+			if asked != tt.asked {
+				t.Errorf("asked = %v, want %v", asked, tt.asked)
+			}
+			if deleted := remote.calls > 0 && local.calls > 0; deleted != tt.deleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package subcmd
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
+	"msgtm/pkg/usecase"
+)
+
+type StatusCommandParameter struct {
+	StateFile string
+}
+
+func StatusCommand(list usecase.ListTagRefs, result *output.StatusList) SubCommand[StatusCommandParameter] {
+	return func(param StatusCommandParameter) error {
+		state, err := ReadStateFile(param.StateFile)
+		if err != nil {
+			return err
+		}
+		statuses, err := usecase.ServiceStatuses(state, list)
+		if err != nil {
+			return fmt.Errorf("failed to get service status: %w", err)
+		}
+		for _, status := range statuses {
+			result.Services = append(result.Services, output.ServiceStatus{
+				Service:  status.Service,
+				Latest:   serviceVersionOf(status.Latest),
+				Recorded: serviceVersionOf(status.Recorded),
+				InSync:   status.InSync(),
+			})
+		}
+		return nil
+	}
+}
+
+func serviceVersionOf(info *usecase.ServiceTagInfo) *domain.ServiceVersion {
+	if info == nil {
+		return nil
+	}
+	version := domain.NewServiceVersion(info.Tag, *info.CommitId)
+	return &version
+}
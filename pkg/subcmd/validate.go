@@ -0,0 +1,94 @@
+package subcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"msgtm/pkg/config"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/schema"
+	"os"
+)
+
+const (
+	StateKind     = "state"
+	ConfigKind    = "config"
+	WorkspaceKind = "workspace"
+)
+
+func schemaOf(kind string) (*schema.Schema, error) {
+	switch kind {
+	case StateKind:
+		return domain.StateSchema(), nil
+	case ConfigKind:
+		return config.Schema(), nil
+	case WorkspaceKind:
+		return config.WorkspaceSchema(), nil
+	}
+	return nil, fmt.Errorf("unknown kind: %s, kind should be %s, %s or %s", kind, StateKind, ConfigKind, WorkspaceKind)
+}
+
+type SchemaCommandParameter struct {
+	Kind string
+}
+
+func SchemaCommand() SubCommand[SchemaCommandParameter] {
+	return func(param SchemaCommandParameter) error {
+		s, err := schemaOf(param.Kind)
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+}
+
+type ValidateCommandParameter struct {
+	Kind string
+	File string
+}
+
+func ValidateCommand() SubCommand[ValidateCommandParameter] {
+	return func(param ValidateCommandParameter) error {
+		var err error
+		switch param.Kind {
+		case StateKind:
+			_, err = ReadStateFile(param.File)
+		case ConfigKind:
+			// config.Load accepts a missing file, validate does not
+			if _, err = os.Stat(param.File); err == nil {
+				_, err = config.Load(param.File)
+			}
+		case WorkspaceKind:
+			_, err = config.LoadWorkspace(param.File)
+		default:
+			_, err = schemaOf(param.Kind)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: ok\n", param.File)
+		return nil
+	}
+}
+
+// ReadStateFile reads the state file after validating it against domain.StateSchema,
+// so that a broken file is reported with the line and field at fault.
+func ReadStateFile(fileName string) (*domain.WritedState, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := schema.ValidateFile(domain.StateSchema(), fileName, data); err != nil {
+		return nil, err
+	}
+	state, err := domain.FromReader(bytes.NewReader(data), domain.YAML)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,204 @@
+package subcmd
+
+import (
+	"fmt"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/manifest"
+	"msgtm/pkg/usecase"
+	"os"
+	"strings"
+	"text/template"
+)
+
+const (
+	DefaultVersionFileValue     = "{{.SemVer}}"
+	DefaultReleaseCommitMessage = "Release {{.Tags}}"
+)
+
+// VersionFile is a file holding the version of a service.
+type VersionFile struct {
+	Service domain.ServiceName
+	File    string
+	// JSON, TOML and Regex locate the version in the file, the whole file is the version when they are empty.
+	JSON  string
+	TOML  string
+	Regex string
+	// Value is the template of the version, DefaultVersionFileValue when empty.
+	// The fields are Service, Version and SemVer, the commit is not known before the files are written.
+	Value string
+}
+
+// ReleaseCommit is the commit of the version files that is tagged instead of HEAD.
+type ReleaseCommit struct {
+	Committer usecase.CreateCommit
+	// Resetter undoes the commit when the tags can not be created.
+	Resetter usecase.ResetCommit
+	// Finder resolves HEAD before the commit, the commit is reset to it.
+	Finder usecase.CommitFinder
+	// Message is the template of the commit message, DefaultReleaseCommitMessage when empty.
+	Message string
+}
+
+// WithVersionFiles sets the version files of the services of the tags to the new versions before they are registered.
+// With a release commit, the files are committed on HEAD and the commit is tagged, only HEAD can be tagged then.
+// The tags are validated with refs before any file is written, and when registering fails
+// the files are restored and the release commit is reset. The changed values are printed to out.
+func WithVersionFiles(register usecase.RegisterServiceTags, files []VersionFile, writer usecase.WriteFile, refs usecase.ListTagRefs, commit *ReleaseCommit, out io.Writer) usecase.RegisterServiceTags {
+	return &versionFilesRegister{register: register, files: files, writer: writer, refs: refs, commit: commit, out: out}
+}
+
+type versionFilesRegister struct {
+	register usecase.RegisterServiceTags
+	files    []VersionFile
+	writer   usecase.WriteFile
+	refs     usecase.ListTagRefs
+	commit   *ReleaseCommit
+	out      io.Writer
+}
+
+func (v *versionFilesRegister) Execute(cmd usecase.RegisterServiceTagsCommand) error {
+	if v.commit != nil && *cmd.CommitId != domain.HEAD {
+		return fmt.Errorf("a release commit is created on HEAD, %s can not be tagged with it", cmd.CommitId.String())
+	}
+	if err := usecase.ValidateNewTags(*cmd.Tags, v.refs); err != nil {
+		return err
+	}
+	names := []string{}
+	originals := map[string][]byte{}
+	contents := map[string][]byte{}
+	changed := map[string]bool{}
+	for _, tag := range *cmd.Tags {
+		for _, file := range v.files {
+			if file.Service != tag.Service {
+				continue
+			}
+			text := file.Value
+			if text == "" {
+				text = DefaultVersionFileValue
+			}
+			value, err := renderVersion(text, tag)
+			if err != nil {
+				return err
+			}
+			data, ok := contents[file.File]
+			if !ok {
+				data, err = os.ReadFile(file.File)
+				if err != nil {
+					return err
+				}
+				names = append(names, file.File)
+				originals[file.File] = data
+			}
+			data, changes, err := setVersion(data, file, value)
+			if err != nil {
+				return fmt.Errorf("failed to set the version of %s in %s: %w", tag.Service, file.File, err)
+			}
+			contents[file.File] = data
+			for _, change := range changes {
+				fmt.Fprintf(v.out, "%s:%d: %s -> %s\n", file.File, change.Line, change.Old, change.New)
+				changed[file.File] = true
+			}
+		}
+	}
+	written := []string{}
+	for _, name := range names {
+		if !changed[name] {
+			continue
+		}
+		if err := v.writer.Execute(usecase.WriteFileCommand{Name: name, Data: contents[name]}); err != nil {
+			return v.rollback(fmt.Errorf("failed to write %s: %w", name, err), written, originals, nil)
+		}
+		written = append(written, name)
+	}
+	var parent *domain.CommitId
+	if v.commit != nil && len(written) > 0 {
+		message, err := v.commitMessage(*cmd.Tags)
+		if err != nil {
+			return v.rollback(err, written, originals, nil)
+		}
+		head := domain.GitTag(domain.HEAD)
+		parent, err = v.commit.Finder.Execute(usecase.FindCommitQuery{Tag: &head})
+		if err != nil {
+			return v.rollback(err, written, originals, nil)
+		}
+		err = v.commit.Committer.Execute(usecase.CreateCommitCommand{Message: message, Files: written})
+		if err != nil {
+			return v.rollback(fmt.Errorf("failed to commit the version files: %w", err), written, originals, nil)
+		}
+	}
+	if err := v.register.Execute(cmd); err != nil {
+		return v.rollback(err, written, originals, parent)
+	}
+	return nil
+}
+
+// rollback resets the release commit to parent unless it is nil and restores the written files.
+func (v *versionFilesRegister) rollback(err error, written []string, originals map[string][]byte, parent *domain.CommitId) error {
+	if parent != nil {
+		if resetErr := v.commit.Resetter.Execute(usecase.ResetCommitCommand{CommitId: parent, Files: written}); resetErr != nil {
+			return fmt.Errorf("%w; failed to reset the release commit to %s: %s", err, parent.String(), resetErr.Error())
+		}
+	}
+	for _, name := range written {
+		if restoreErr := v.writer.Execute(usecase.WriteFileCommand{Name: name, Data: originals[name]}); restoreErr != nil {
+			return fmt.Errorf("%w; failed to restore %s: %s", err, name, restoreErr.Error())
+		}
+	}
+	return err
+}
+
+func (v *versionFilesRegister) commitMessage(tags []*domain.ServiceTagWithSemVer) (string, error) {
+	text := v.commit.Message
+	if text == "" {
+		text = DefaultReleaseCommitMessage
+	}
+	t, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid release commit message %s: %w", text, err)
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.String())
+	}
+	b := &strings.Builder{}
+	if err := t.Execute(b, struct{ Tags string }{Tags: strings.Join(names, ", ")}); err != nil {
+		return "", fmt.Errorf("invalid release commit message %s: %w", text, err)
+	}
+	return b.String(), nil
+}
+
+// renderVersion executes the template text of a version file with the fields of the service tag.
+func renderVersion(text string, tag *domain.ServiceTagWithSemVer) (string, error) {
+	t, err := template.New("value").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid version file value %s: %w", text, err)
+	}
+	b := &strings.Builder{}
+	err = t.Execute(b, struct{ Service, Version, SemVer string }{
+		Service: string(tag.Service),
+		Version: tag.Version.String(),
+		SemVer:  strings.TrimPrefix(tag.Version.String(), "v"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid version file value %s: %w", text, err)
+	}
+	return b.String(), nil
+}
+
+// setVersion replaces the version in the content of a version file.
+func setVersion(data []byte, file VersionFile, value string) ([]byte, []manifest.Change, error) {
+	switch {
+	case file.JSON != "":
+		return manifest.SetPath(data, file.JSON, value)
+	case file.TOML != "":
+		return manifest.SetTOMLKey(data, file.TOML, value)
+	case file.Regex != "":
+		return manifest.ReplaceRegex(data, file.Regex, value)
+	}
+	old := strings.TrimSpace(string(data))
+	if old == value {
+		return data, nil, nil
+	}
+	return []byte(value + "\n"), []manifest.Change{{Line: 1, Old: old, New: value}}, nil
+}
@@ -0,0 +1,170 @@
+package subcmd
+
+import (
+	"errors"
+	"io"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type memoryWriter map[string]string
+
+func (m memoryWriter) Execute(cmd usecase.WriteFileCommand) error {
+	m[cmd.Name] = string(cmd.Data)
+	return nil
+}
+
+type recordingCommitter struct {
+	commits []usecase.CreateCommitCommand
+}
+
+func (r *recordingCommitter) Execute(cmd usecase.CreateCommitCommand) error {
+	r.commits = append(r.commits, cmd)
+	return nil
+}
+
+type recordingResetter struct {
+	resets []domain.CommitId
+}
+
+func (r *recordingResetter) Execute(cmd usecase.ResetCommitCommand) error {
+	r.resets = append(r.resets, *cmd.CommitId)
+	return nil
+}
+
+type stubFinder domain.CommitId
+
+func (s stubFinder) Execute(usecase.FindCommitQuery) (*domain.CommitId, error) {
+	commitId := domain.CommitId(s)
+	return &commitId, nil
+}
+
+type stubRegister struct {
+	err        error
+	registered []*domain.ServiceTagWithSemVer
+}
+
+func (s *stubRegister) Execute(cmd usecase.RegisterServiceTagsCommand) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.registered = append(s.registered, *cmd.Tags...)
+	return nil
+}
+
+// versionFilesFixture writes a plain VERSION file of api and a package.json shared by api and web.
+func versionFilesFixture(t *testing.T) (string, string, []VersionFile) {
+	t.Helper()
+	dir := t.TempDir()
+	version := filepath.Join(dir, "VERSION")
+	packageJSON := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(version, []byte("1.0.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(packageJSON, []byte("{\n  \"api\": \"1.0.0\",\n  \"web\": \"2.0.0\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return version, packageJSON, []VersionFile{
+		{Service: "api", File: version},
+		{Service: "api", File: packageJSON, JSON: "api"},
+		{Service: "web", File: packageJSON, JSON: "web", Value: "{{.Version}}"},
+	}
+}
+
+func versionTags() *[]*domain.ServiceTagWithSemVer {
+	return &[]*domain.ServiceTagWithSemVer{
+		domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 1, 0)),
+		domain.NewServiceTagWithSemVer("web", domain.NewSemVer(2, 1, 0)),
+	}
+}
+
+func TestWithVersionFiles(t *testing.T) {
+	version, packageJSON, files := versionFilesFixture(t)
+	writer := memoryWriter{}
+	committer := &recordingCommitter{}
+	register := &stubRegister{}
+	commit := &ReleaseCommit{Committer: committer, Resetter: &recordingResetter{}, Finder: stubFinder("parent")}
+	head := domain.HEAD
+
+	err := WithVersionFiles(register, files, writer, stubTagRefs{}, commit, io.Discard).Execute(usecase.RegisterServiceTagsCommand{CommitId: &head, Tags: versionTags()})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	want := memoryWriter{
+		version:     "1.1.0\n",
+		packageJSON: "{\n  \"api\": \"1.1.0\",\n  \"web\": \"v2.1.0\"\n}\n",
+	}
+	if !reflect.DeepEqual(writer, want) {
+		t.Errorf("written = %q, want %q", writer, want)
+	}
+	wantCommits := []usecase.CreateCommitCommand{{Message: "Release api-v1.1.0, web-v2.1.0", Files: []string{version, packageJSON}}}
+	if !reflect.DeepEqual(committer.commits, wantCommits) {
+		t.Errorf("commits = %v, want %v", committer.commits, wantCommits)
+	}
+	if len(register.registered) != 2 {
+		t.Errorf("registered = %v, want the tags after the commit", register.registered)
+	}
+}
+
+func TestWithVersionFilesTagsOnlyHEADWithReleaseCommit(t *testing.T) {
+	_, _, files := versionFilesFixture(t)
+	writer := memoryWriter{}
+	committer := &recordingCommitter{}
+	register := &stubRegister{}
+	commit := &ReleaseCommit{Committer: committer, Resetter: &recordingResetter{}, Finder: stubFinder("parent")}
+	commitId := domain.CommitId("0000001")
+
+	err := WithVersionFiles(register, files, writer, stubTagRefs{}, commit, io.Discard).Execute(usecase.RegisterServiceTagsCommand{CommitId: &commitId, Tags: versionTags()})
+	if err == nil {
+		t.Fatal("Execute() error = nil, want an error for a commit other than HEAD")
+	}
+	if len(writer) > 0 || len(committer.commits) > 0 || len(register.registered) > 0 {
+		t.Errorf("written %v, committed %v and registered %v, want nothing", writer, committer.commits, register.registered)
+	}
+}
+
+func TestWithVersionFilesValidatesTagsBeforeWriting(t *testing.T) {
+	_, _, files := versionFilesFixture(t)
+	writer := memoryWriter{}
+	committer := &recordingCommitter{}
+	commit := &ReleaseCommit{Committer: committer, Resetter: &recordingResetter{}, Finder: stubFinder("parent")}
+	refs := stubTagRefs{{Tag: "web-v2.1.0", CommitId: "0000001"}}
+	head := domain.HEAD
+
+	err := WithVersionFiles(&stubRegister{}, files, writer, refs, commit, io.Discard).Execute(usecase.RegisterServiceTagsCommand{CommitId: &head, Tags: versionTags()})
+	var invalid *usecase.InvalidTagsError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Execute() error = %v, want InvalidTagsError", err)
+	}
+	if len(writer) > 0 || len(committer.commits) > 0 {
+		t.Errorf("written %v and committed %v, want nothing", writer, committer.commits)
+	}
+}
+
+func TestWithVersionFilesRollsBackWhenTaggingFails(t *testing.T) {
+	version, packageJSON, files := versionFilesFixture(t)
+	writer := memoryWriter{}
+	resetter := &recordingResetter{}
+	commit := &ReleaseCommit{Committer: &recordingCommitter{}, Resetter: resetter, Finder: stubFinder("parent")}
+	register := &stubRegister{err: errors.New("tag failed")}
+	head := domain.HEAD
+
+	err := WithVersionFiles(register, files, writer, stubTagRefs{}, commit, io.Discard).Execute(usecase.RegisterServiceTagsCommand{CommitId: &head, Tags: versionTags()})
+	if !errors.Is(err, register.err) {
+		t.Fatalf("Execute() error = %v, want %v", err, register.err)
+	}
+	if want := []domain.CommitId{"parent"}; !reflect.DeepEqual(resetter.resets, want) {
+		t.Errorf("resets = %v, want %v", resetter.resets, want)
+	}
+	want := memoryWriter{
+		version:     "1.0.0\n",
+		packageJSON: "{\n  \"api\": \"1.0.0\",\n  \"web\": \"2.0.0\"\n}\n",
+	}
+	if !reflect.DeepEqual(writer, want) {
+		t.Errorf("written = %q, want the original files restored %q", writer, want)
+	}
+}
@@ -3,6 +3,7 @@ package subcmd
 import (
 	"fmt"
 	"msgtm/pkg/domain"
+	"msgtm/pkg/output"
 	"msgtm/pkg/usecase"
 )
 
@@ -12,10 +13,26 @@ type VersionUpCommandParameter struct {
 	IsAll    bool
 	CommitId string
 	Services []string
+	// Remote computes the next versions from the tags of the remote instead of the local tags when not empty.
+	Remote string
+	PushParameter
+	PublishParameter
 }
 
-func VersionUpCommand(list usecase.ListTags, register usecase.RegisterServiceTags, getter usecase.CommitTagGetter) SubCommand[VersionUpCommandParameter] {
+func VersionUpCommand(list usecase.ListTags, register usecase.RegisterServiceTags, getter usecase.CommitTagGetter, remoteList usecase.ListRemoteTagRefs, pusher usecase.CommitPusher, destroyer usecase.DestroyServiceTags, refs usecase.ListTagRefs, commits usecase.ListCommits, publishers Publishers, notifier usecase.NotifyRelease, versionsWriter usecase.WriteVersions, result *output.ChangeList) SubCommand[VersionUpCommandParameter] {
 	return func(param VersionUpCommandParameter) error {
+		if err := param.requirePush(param.PushParameter); err != nil {
+			return err
+		}
+		recorder := &usecase.RecordingRegister{Register: register}
+		publisher, err := param.publisher(publishers, param.remote())
+		if err != nil {
+			return err
+		}
+		if param.Remote != "" {
+			remote := domain.RemoteAddr(param.Remote)
+			list = usecase.TagNames(usecase.RemoteTagRefs(remoteList, &remote))
+		}
 		commitId := domain.HEAD
 		if param.CommitId != "" {
 			commitId = domain.CommitId(param.CommitId)
@@ -37,9 +54,9 @@ func VersionUpCommand(list usecase.ListTags, register usecase.RegisterServiceTag
 			f = domain.MajorUpAll
 		}
 
-		err := usecase.VersionUpAllServiceTags(
+		err = usecase.VersionUpAllServiceTags(
 			list,
-			register,
+			recorder,
 			f,
 			&commitId,
 			excludeServices...,
@@ -47,6 +64,22 @@ func VersionUpCommand(list usecase.ListTags, register usecase.RegisterServiceTag
 		if err != nil {
 			return fmt.Errorf("failed to version up: %w", err)
 		}
-		return nil
+		created, err := recordCreated(result, refs, recorder.Registered)
+		if err != nil {
+			return err
+		}
+		err = param.push(pusher, destroyer, recorder.Registered, result, created)
+		if err != nil {
+			return err
+		}
+		err = writeVersions(versionsWriter, refs, recorder.Registered)
+		if err != nil {
+			return err
+		}
+		err = param.publish(publisher, refs, commits, recorder.Registered)
+		if err != nil {
+			return err
+		}
+		return notify(notifier, domain.ReleaseUpgraded, param.Push, refs, commits, recorder.Registered)
 	}
 }
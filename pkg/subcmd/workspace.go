@@ -0,0 +1,176 @@
+package subcmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"msgtm/pkg/config"
+	"msgtm/pkg/domain"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceCommands are the commands that can run across the repositories of a workspace.
+var WorkspaceCommands = []string{"list", "status", "upgrade", "push"}
+
+// stateCommands are the workspace commands that change tags, the combined state file is written after them.
+var stateCommands = []string{"upgrade", "push"}
+
+// RepoRunner runs msgtm with args in the repository at dir, writing its output to out.
+type RepoRunner func(dir string, args []string, out io.Writer) error
+
+type WorkspaceCommandParameter struct {
+	File string
+	// Args are the command and its flags, e.g. push -r origin.
+	Args []string
+	// DryRun does not write the combined state file.
+	DryRun bool
+}
+
+func WorkspaceCommand(run RepoRunner) SubCommand[WorkspaceCommandParameter] {
+	return func(param WorkspaceCommandParameter) error {
+		if len(param.Args) == 0 || !contains(WorkspaceCommands, param.Args[0]) {
+			return fmt.Errorf("workspace command should be one of %s", strings.Join(WorkspaceCommands, ", "))
+		}
+		workspace, err := config.LoadWorkspace(param.File)
+		if err != nil {
+			return err
+		}
+		failed := []string{}
+		succeeded := []string{}
+		for _, repo := range workspace.Repos {
+			out := newPrefixWriter(os.Stdout, fmt.Sprintf("[%s] ", repo.Name))
+			err := run(workspace.RepoPath(param.File, repo), repoArgs(param.Args, repo), out)
+			out.Flush()
+			if err != nil {
+				fmt.Printf("[%s] %s failed: %s\n", repo.Name, param.Args[0], err.Error())
+				failed = append(failed, repo.Name)
+				continue
+			}
+			succeeded = append(succeeded, repo.Name)
+		}
+
+		stateFile := workspace.StatePath(param.File)
+		switch {
+		case !contains(stateCommands, param.Args[0]):
+		case param.DryRun:
+			fmt.Printf("Dry run, %s is not written\n", stateFile)
+		default:
+			if err := writeWorkspaceState(param.File, workspace, stateFile, succeeded); err != nil {
+				return err
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%s failed in %s", param.Args[0], strings.Join(failed, ", "))
+		}
+		return nil
+	}
+}
+
+// repoArgs adds the state file of the repository to the commands that read it.
+func repoArgs(args []string, repo config.WorkspaceRepo) []string {
+	if repo.StateFile == "" || !contains([]string{"status", "upgrade"}, args[0]) {
+		return args
+	}
+	return append(append([]string{}, args...), "--state-file", repo.StateFile)
+}
+
+// writeWorkspaceState combines the state files of the succeeded repositories into stateFile.
+// The other repositories keep their previous states, repositories without a state file are left out.
+func writeWorkspaceState(fileName string, workspace *config.Workspace, stateFile string, succeeded []string) error {
+	previous, err := readWorkspaceState(stateFile)
+	if err != nil {
+		return err
+	}
+	combined := &domain.WorkspaceState{}
+	for _, repo := range workspace.Repos {
+		if !contains(succeeded, repo.Name) {
+			if state := previous.Repo(repo.Name); state != nil {
+				combined.Repos = append(combined.Repos, state)
+			}
+			continue
+		}
+		path := workspace.RepoPath(fileName, repo)
+		state, err := ReadStateFile(filepath.Join(path, repo.StateFileName()))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the state of %s: %w", repo.Name, err)
+		}
+		combined.Repos = append(combined.Repos, &domain.RepoState{
+			Name:  repo.Name,
+			Path:  repo.Path,
+			State: state,
+		})
+	}
+	file, err := os.Create(stateFile)
+	if err != nil {
+		return fmt.Errorf("failed to create workspace state file: %w", err)
+	}
+	defer file.Close()
+	return combined.Write(file)
+}
+
+// readWorkspaceState reads the combined state file, which is empty before it is first written.
+func readWorkspaceState(stateFile string) (*domain.WorkspaceState, error) {
+	file, err := os.Open(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &domain.WorkspaceState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace state file: %w", err)
+	}
+	defer file.Close()
+	state, err := domain.WorkspaceStateFromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", stateFile, err)
+	}
+	return state, nil
+}
+
+// prefixWriter writes every line with a prefix, so that the output of repositories can be told apart.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		line, err := p.buf.ReadString('\n')
+		if err != nil {
+			// keep the incomplete line until the rest of it is written
+			p.buf.Reset()
+			p.buf.WriteString(line)
+			return len(b), nil
+		}
+		if _, err := io.WriteString(p.w, p.prefix+line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the last line when it does not end with a newline.
+func (p *prefixWriter) Flush() {
+	if p.buf.Len() > 0 {
+		io.WriteString(p.w, p.prefix+p.buf.String()+"\n")
+		p.buf.Reset()
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package subcmd
+
+import (
+	"errors"
+	"io"
+	"msgtm/pkg/domain"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeState(t *testing.T, fileName string, services ...domain.ServiceName) {
+	t.Helper()
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := domain.InitStateWriter(services...).Write(file, domain.YAML); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkspaceCommandWritesStatesOfSucceededRepos(t *testing.T) {
+	dir := t.TempDir()
+	workspaceFile := filepath.Join(dir, "msgtm-workspace.yaml")
+	err := os.WriteFile(workspaceFile, []byte("repos:\n  - name: a\n    path: a\n  - name: b\n    path: b\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(dir, repo), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeState(t, filepath.Join(dir, repo, "services-state.yaml"), "old")
+	}
+	stateFile := filepath.Join(dir, "workspace-state.yaml")
+
+	ran := 0
+	run := func(repo string, args []string, out io.Writer) error {
+		ran++
+		// both repositories change their states, but b fails
+		writeState(t, filepath.Join(repo, "services-state.yaml"), "new")
+		if filepath.Base(repo) == "b" {
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+	if err := WorkspaceCommand(run)(WorkspaceCommandParameter{File: workspaceFile, Args: []string{"list"}}); err == nil {
+		t.Error("WorkspaceCommand() error = nil, want the failure of b")
+	}
+	if _, err := os.Stat(stateFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("list wrote %s, want it left alone", stateFile)
+	}
+
+	// b keeps the state of the previous run that recorded it
+	previous := &domain.WorkspaceState{Repos: []*domain.RepoState{
+		{Name: "b", Path: "b", State: domain.InitStateWriter("old")},
+	}}
+	file, err := os.Create(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := previous.Write(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if err := WorkspaceCommand(run)(WorkspaceCommandParameter{File: workspaceFile, Args: []string{"upgrade"}}); err == nil {
+		t.Error("WorkspaceCommand() error = nil, want the failure of b")
+	}
+	state, err := readWorkspaceState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Repos) != 2 {
+		t.Fatalf("workspace state = %+v, want a and b", state.Repos)
+	}
+	for repo, want := range map[string]domain.ServiceName{"a": "new", "b": "old"} {
+		got := *state.Repo(repo).State.ServiceTagStates[0].ServiceName
+		if got != want {
+			t.Errorf("service of %s = %s, want %s", repo, got, want)
+		}
+	}
+	if ran != 4 {
+		t.Errorf("ran %d times, want 4", ran)
+	}
+}
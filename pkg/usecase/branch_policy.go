@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// BranchPolicyError is returned when the commit to tag is not on an allowed branch.
+type BranchPolicyError struct {
+	CommitId domain.CommitId
+	Patterns []string
+	// Checked are the branches matching the patterns, none of them contains the commit.
+	Checked []domain.Branch
+}
+
+func (e *BranchPolicyError) Error() string {
+	if len(e.Checked) == 0 {
+		return fmt.Sprintf("can not tag %s: no local or remote-tracking branch matches the allowed branches %s",
+			e.CommitId.String(), strings.Join(e.Patterns, ", "))
+	}
+	checked := make([]string, 0, len(e.Checked))
+	for _, branch := range e.Checked {
+		checked = append(checked, branch.String())
+	}
+	return fmt.Sprintf("can not tag %s: it is not on any allowed branch (%s), checked %s",
+		e.CommitId.String(), strings.Join(e.Patterns, ", "), strings.Join(checked, ", "))
+}
+
+// BranchPolicyRegister registers tags through Register only on commits that are reachable
+// from a local or remote-tracking branch matching one of AllowedBranches, e.g. main or release/*.
+type BranchPolicyRegister struct {
+	Register        RegisterServiceTags
+	AllowedBranches []string
+	Branches        ListBranches
+	Ancestor        IsAncestor
+}
+
+func (b *BranchPolicyRegister) Execute(cmd RegisterServiceTagsCommand) error {
+	if err := b.check(cmd.CommitId); err != nil {
+		return err
+	}
+	return b.Register.Execute(cmd)
+}
+
+func (b *BranchPolicyRegister) check(commitId *domain.CommitId) error {
+	branches, err := b.Branches.Execute(ListBranchesQuery{})
+	if err != nil {
+		return err
+	}
+	checked := []domain.Branch{}
+	for _, branch := range *branches {
+		if !branch.Matches(b.AllowedBranches) {
+			continue
+		}
+		checked = append(checked, branch)
+		ok, err := b.Ancestor.Execute(IsAncestorQuery{CommitId: commitId, Revision: branch.Ref()})
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return &BranchPolicyError{CommitId: *commitId, Patterns: b.AllowedBranches, Checked: checked}
+}
@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type StubBranchList struct {
+	branches []domain.Branch
+}
+
+func (s *StubBranchList) Execute(query usecase.ListBranchesQuery) (*[]domain.Branch, error) {
+	return &s.branches, nil
+}
+
+func TestBranchPolicyRegister(t *testing.T) {
+	branches := &StubBranchList{branches: []domain.Branch{
+		{Name: "main"},
+		{Name: "feature/x"},
+		{Name: "release/1.0", Remote: "origin"},
+	}}
+	ancestor := &StubBranches{commits: map[string][]domain.CommitId{
+		"refs/heads/main":                 {"0000001"},
+		"refs/heads/feature/x":            {"0000001", "feature"},
+		"refs/remotes/origin/release/1.0": {"0000001", "release"},
+	}}
+	tests := []struct {
+		commitId domain.CommitId
+		allowed  bool
+	}{
+		{"0000001", true},
+		{"release", true},
+		{"feature", false},
+	}
+	for _, tt := range tests {
+		register := &MockRegister{}
+		sut := &usecase.BranchPolicyRegister{
+			Register:        register,
+			AllowedBranches: []string{"main", "release/*"},
+			Branches:        branches,
+			Ancestor:        ancestor,
+		}
+		err := sut.Execute(usecase.RegisterServiceTagsCommand{CommitId: &tt.commitId, Tags: serviceTagsOf("service-a-v1.0.0")})
+		if tt.allowed {
+			if err != nil || register.AddedTags == nil {
+				t.Errorf("Execute(%s) error = %v, want registered", tt.commitId, err)
+			}
+			continue
+		}
+		var policyErr *usecase.BranchPolicyError
+		if !errors.As(err, &policyErr) {
+			t.Fatalf("Execute(%s) error = %v, want BranchPolicyError", tt.commitId, err)
+		}
+		if len(policyErr.Checked) != 2 {
+			t.Errorf("Checked = %v, want main and origin/release/1.0", policyErr.Checked)
+		}
+		if register.AddedTags != nil {
+			t.Errorf("registered %v on a commit of no allowed branch", *register.AddedTags)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// Plan records the mutating operations of a dry run instead of executing them.
+// The command executors of a Plan only record, while the query executors
+// wrapped by Refs see the local tags as if the recorded operations had been executed.
+type Plan struct {
+	Operations []string
+	// finder resolves the commits of created tags, e.g. HEAD.
+	finder  CommitFinder
+	created map[domain.GitTag]domain.CommitId
+	deleted map[domain.GitTag]bool
+}
+
+func NewPlan(finder CommitFinder) *Plan {
+	return &Plan{
+		finder:  finder,
+		created: map[domain.GitTag]domain.CommitId{},
+		deleted: map[domain.GitTag]bool{},
+	}
+}
+
+// Record adds an operation to the plan.
+// Commands record the operations that are not executed through executors, e.g. file writes.
+func (p *Plan) Record(format string, args ...any) {
+	p.Operations = append(p.Operations, fmt.Sprintf(format, args...))
+}
+
+func (p *Plan) Register() RegisterServiceTags {
+	return &planRegister{p}
+}
+
+func (p *Plan) LocalDestroyer() DestroyServiceTags {
+	return &planDestroyer{plan: p}
+}
+
+func (p *Plan) RemoteDestroyer(remote *domain.RemoteAddr) DestroyServiceTags {
+	return &planDestroyer{plan: p, remote: remote}
+}
+
+func (p *Plan) Pusher() CommitPusher {
+	return &planPusher{p}
+}
+
+func (p *Plan) Fetcher() FetchTags {
+	return &planFetcher{p}
+}
+
+// Publisher records the releases published on the hosting service described by service, e.g. origin (gitlab).
+func (p *Plan) Publisher(service string) PublishRelease {
+	return &planPublisher{plan: p, service: service}
+}
+
+func (p *Plan) ImageCopier() CopyImage {
+	return &planImageCopier{p}
+}
+
+// Notifier records the release events sent to the notification sink described by sink, e.g. slack.
+func (p *Plan) Notifier(sink string) NotifyRelease {
+	return &planNotifier{plan: p, sink: sink}
+}
+
+// VersionsWriter records the versions handed to the CI pipeline described by target, e.g. $GITHUB_OUTPUT.
+func (p *Plan) VersionsWriter(target string) WriteVersions {
+	return &planVersionsWriter{plan: p, target: target}
+}
+
+// FileWriter records the files written to the repository.
+func (p *Plan) FileWriter() WriteFile {
+	return &planFileWriter{p}
+}
+
+func (p *Plan) Committer() CreateCommit {
+	return &planCommitter{p}
+}
+
+func (p *Plan) CommitResetter() ResetCommit {
+	return &planCommitResetter{p}
+}
+
+// Refs lists the local tags of list with the planned tag creations and deletions applied.
+func (p *Plan) Refs(list ListTagRefs) ListTagRefs {
+	return &planRefs{plan: p, list: list}
+}
+
+type planRegister struct {
+	plan *Plan
+}
+
+func (r *planRegister) Execute(cmd RegisterServiceTagsCommand) error {
+	// the commit finder resolves any revision, not only tags
+	revision := domain.GitTag(cmd.CommitId.String())
+	commitId, err := r.plan.finder.Execute(FindCommitQuery{Tag: &revision})
+	if err != nil {
+		return err
+	}
+	for _, tag := range *cmd.Tags {
+		r.plan.Record("create tag %s at %s", tag.String(), commitId.String())
+		r.plan.created[tag.ToGitTag()] = *commitId
+		delete(r.plan.deleted, tag.ToGitTag())
+	}
+	return nil
+}
+
+type planDestroyer struct {
+	plan *Plan
+	// remote is nil for the local repository
+	remote *domain.RemoteAddr
+}
+
+func (d *planDestroyer) Execute(cmd DestroyServiceTagsCommand) error {
+	for _, tag := range *cmd.Tags {
+		if d.remote != nil {
+			d.plan.Record("delete tag %s on %s", tag.String(), d.remote.String())
+			continue
+		}
+		d.plan.Record("delete tag %s", tag.String())
+		d.plan.deleted[tag.ToGitTag()] = true
+		delete(d.plan.created, tag.ToGitTag())
+	}
+	return nil
+}
+
+type planPusher struct {
+	plan *Plan
+}
+
+func (p *planPusher) Execute(cmd CommitPushCommand) error {
+	if len(*cmd.Tags) == 0 {
+		return nil
+	}
+	mode := ""
+	if cmd.Atomic {
+		mode = " atomically"
+	}
+	p.plan.Record("push %s to %s%s", joinTags(*cmd.Tags), cmd.RemoteAddr.String(), mode)
+	return nil
+}
+
+type planFetcher struct {
+	plan *Plan
+}
+
+func (f *planFetcher) Execute(cmd FetchTagsCommand) error {
+	if len(*cmd.Tags) == 0 {
+		return nil
+	}
+	tags := make([]string, 0, len(*cmd.Tags))
+	for _, tag := range *cmd.Tags {
+		tags = append(tags, tag.String())
+	}
+	mode := ""
+	if cmd.Force {
+		mode = ", overwriting local tags"
+	}
+	f.plan.Record("fetch %s from %s%s", strings.Join(tags, ", "), cmd.RemoteAddr.String(), mode)
+	return nil
+}
+
+type planPublisher struct {
+	plan    *Plan
+	service string
+}
+
+func (p *planPublisher) Execute(cmd PublishReleaseCommand) error {
+	details := []string{fmt.Sprintf("%d commits", len(cmd.Release.Changelog))}
+	if cmd.Release.Prerelease() {
+		details = append(details, "pre-release")
+	}
+	for _, asset := range cmd.Assets {
+		details = append(details, "asset "+asset)
+	}
+	p.plan.Record("publish release %s on %s (%s)", cmd.Release.Tag.String(), p.service, strings.Join(details, ", "))
+	return nil
+}
+
+type planImageCopier struct {
+	plan *Plan
+}
+
+func (c *planImageCopier) Execute(cmd CopyImageCommand) error {
+	c.plan.Record("copy image %s to %s", cmd.Source.String(), cmd.Target.Tag)
+	return nil
+}
+
+type planNotifier struct {
+	plan *Plan
+	sink string
+}
+
+func (n *planNotifier) Execute(cmd NotifyReleaseCommand) error {
+	n.plan.Record("notify %s of %s (%s, %d commits)", n.sink, cmd.Event.Tag.String(), cmd.Event.Action, len(cmd.Event.Changelog))
+	return nil
+}
+
+type planVersionsWriter struct {
+	plan   *Plan
+	target string
+}
+
+func (w *planVersionsWriter) Execute(cmd WriteVersionsCommand) error {
+	tags := make([]string, 0, len(cmd.Versions))
+	for _, version := range cmd.Versions {
+		tags = append(tags, string(version.Tag))
+	}
+	if len(tags) == 0 {
+		tags = append(tags, "no versions")
+	}
+	w.plan.Record("write %s to %s", strings.Join(tags, ", "), w.target)
+	return nil
+}
+
+type planFileWriter struct {
+	plan *Plan
+}
+
+func (w *planFileWriter) Execute(cmd WriteFileCommand) error {
+	w.plan.Record("write %s", cmd.Name)
+	return nil
+}
+
+type planCommitter struct {
+	plan *Plan
+}
+
+func (c *planCommitter) Execute(cmd CreateCommitCommand) error {
+	c.plan.Record("commit %s: %s", strings.Join(cmd.Files, ", "), cmd.Message)
+	return nil
+}
+
+type planCommitResetter struct {
+	plan *Plan
+}
+
+func (r *planCommitResetter) Execute(cmd ResetCommitCommand) error {
+	r.plan.Record("reset HEAD to %s", cmd.CommitId.String())
+	return nil
+}
+
+type planRefs struct {
+	plan *Plan
+	list ListTagRefs
+}
+
+func (r *planRefs) Execute(query ListTagRefsQuery) (*[]domain.TagRef, error) {
+	refs, err := r.list.Execute(query)
+	if err != nil {
+		return nil, err
+	}
+	result := []domain.TagRef{}
+	for _, ref := range *refs {
+		if r.plan.deleted[ref.Tag] {
+			continue
+		}
+		if _, ok := r.plan.created[ref.Tag]; ok {
+			continue
+		}
+		result = append(result, ref)
+	}
+	for tag, commitId := range r.plan.created {
+		ref := domain.TagRef{Tag: tag, CommitId: commitId}
+		if len(query.Services) == 0 || ref.IsServiceTagOf(query.Services) {
+			result = append(result, ref)
+		}
+	}
+	return &result, nil
+}
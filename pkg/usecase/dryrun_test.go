@@ -0,0 +1,105 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+type StubCommitFinder struct {
+	commitId domain.CommitId
+}
+
+func (s *StubCommitFinder) Execute(query usecase.FindCommitQuery) (*domain.CommitId, error) {
+	return &s.commitId, nil
+}
+
+func TestPlanRecordsOperationsAndOverlaysRefs(t *testing.T) {
+	plan := usecase.NewPlan(&StubCommitFinder{commitId: "abc123"})
+	local := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: "0000001"},
+		{Tag: "service-b-v1.0.0", CommitId: "0000001"},
+	}}
+
+	commitId := domain.HEAD
+	err := usecase.CreateServiceTags(plan.Register(), &commitId, []domain.ServiceName{"service-a"}, domain.NewSemVer(1, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.LocalDestroyer().Execute(usecase.DestroyServiceTagsCommand{
+		Tags: &[]*domain.ServiceTagWithSemVer{domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 0, 0))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	origin := domain.Origin
+	err = plan.Pusher().Execute(usecase.CommitPushCommand{
+		RemoteAddr: &origin,
+		Tags:       &[]*domain.ServiceTagWithSemVer{domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 1, 0))},
+		Atomic:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.Publisher("origin (github)").Execute(usecase.PublishReleaseCommand{
+		Release: &domain.Release{Tag: domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 1, 0))},
+		Assets:  []string{"dist/a.tar.gz"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.ImageCopier().Execute(usecase.CopyImageCommand{
+		Source: domain.Image{Repository: "registry.example.com/service-a", Tag: "abc123"},
+		Target: domain.Image{Repository: "registry.example.com/service-a", Tag: "v1.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.Notifier("slack").Execute(usecase.NotifyReleaseCommand{
+		Event: &domain.ReleaseEvent{Action: domain.ReleasePushed, Tag: "service-a-v1.1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.VersionsWriter("msgtm.env").Execute(usecase.WriteVersionsCommand{
+		Versions: []domain.ServiceVersion{{Service: "service-a", Version: "1.1.0", Tag: "service-a-v1.1.0"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.FileWriter().Execute(usecase.WriteFileCommand{Name: "deploy/values.yaml", Data: []byte("tag: v1.1.0\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plan.Committer().Execute(usecase.CreateCommitCommand{Message: "Release service-a-v1.1.0", Files: []string{"service-a/VERSION"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOperations := []string{
+		"create tag service-a-v1.1.0 at abc123",
+		"delete tag service-b-v1.0.0",
+		"push service-a-v1.1.0 to origin atomically",
+		"publish release service-a-v1.1.0 on origin (github) (0 commits, asset dist/a.tar.gz)",
+		"copy image registry.example.com/service-a:abc123 to v1.1.0",
+		"notify slack of service-a-v1.1.0 (push, 0 commits)",
+		"write service-a-v1.1.0 to msgtm.env",
+		"write deploy/values.yaml",
+		"commit service-a/VERSION: Release service-a-v1.1.0",
+	}
+	if !reflect.DeepEqual(plan.Operations, expectedOperations) {
+		t.Errorf("Operations = %v, want %v", plan.Operations, expectedOperations)
+	}
+	refs, err := plan.Refs(local).Execute(usecase.ListTagRefsQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRefs := []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: "0000001"},
+		{Tag: "service-a-v1.1.0", CommitId: "abc123"},
+	}
+	if !cmpArrayContent(*refs, expectedRefs) {
+		t.Errorf("Refs() = %v, want %v", *refs, expectedRefs)
+	}
+}
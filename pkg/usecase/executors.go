@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"fmt"
 	"msgtm/pkg/domain"
 )
 
@@ -45,6 +46,8 @@ type CommitPusher = CommandExecutor[CommitPushCommand]
 type CommitPushCommand struct {
 	RemoteAddr *domain.RemoteAddr
 	Tags       *[]*domain.ServiceTagWithSemVer
+	// Atomic updates either all of the tags on the remote or none of them.
+	Atomic bool
 }
 
 // RegisterServiceTags is a usecase that registers the specified tags.
@@ -53,3 +56,109 @@ type RegisterServiceTagsCommand struct {
 	CommitId *domain.CommitId
 	Tags     *[]*domain.ServiceTagWithSemVer
 }
+
+// ListTagRefs is a usecase that lists tags with the commits they point at in one query.
+type ListTagRefs = QueryExecutor[ListTagRefsQuery, *[]domain.TagRef]
+type ListTagRefsQuery struct {
+	// Services limits the result to the service tags of these services.
+	// All tags are listed when empty.
+	Services []domain.ServiceName
+}
+
+// ListRemoteTagRefs is a usecase that lists the tags of a remote repository with the commits they point at.
+// Only Tag, CommitId and Type of the refs are known from a remote.
+type ListRemoteTagRefs = QueryExecutor[ListRemoteTagRefsQuery, *[]domain.TagRef]
+type ListRemoteTagRefsQuery struct {
+	RemoteAddr *domain.RemoteAddr
+	// Services limits the result to the service tags of these services.
+	// All tags are listed when empty.
+	Services []domain.ServiceName
+}
+
+// FetchTags is a usecase that fetches the specified tags from the remote repository.
+type FetchTags = CommandExecutor[FetchTagsCommand]
+type FetchTagsCommand struct {
+	RemoteAddr *domain.RemoteAddr
+	Tags       *[]domain.GitTag
+	// Force overwrites local tags that point at other commits.
+	Force bool
+}
+
+// IsAncestor is a usecase that checks whether a commit is reachable from a revision, e.g. a branch.
+// A revision that does not exist contains no commit.
+type IsAncestor = QueryExecutor[IsAncestorQuery, bool]
+type IsAncestorQuery struct {
+	CommitId *domain.CommitId
+	Revision string
+}
+
+// ListBranches is a usecase that lists the local and remote-tracking branches.
+type ListBranches = QueryExecutor[ListBranchesQuery, *[]domain.Branch]
+type ListBranchesQuery struct{}
+
+// ListCommits is a usecase that lists the commits reachable from To but not from From, newest first.
+type ListCommits = QueryExecutor[ListCommitsQuery, *[]domain.Commit]
+type ListCommitsQuery struct {
+	// From excludes the commits reachable from it, every commit of To is listed when nil.
+	From *domain.CommitId
+	To   *domain.CommitId
+}
+
+// PublishRelease is a usecase that publishes a release on a hosting service.
+type PublishRelease = CommandExecutor[PublishReleaseCommand]
+type PublishReleaseCommand struct {
+	Release *domain.Release
+	// Assets are the files uploaded to the release.
+	Assets []string
+}
+
+// ImageExists is a usecase that checks whether an image is in its registry.
+type ImageExists = QueryExecutor[ImageExistsQuery, bool]
+type ImageExistsQuery struct {
+	Image domain.Image
+}
+
+// CopyImage is a usecase that tags an image of a registry with another tag of the same repository.
+type CopyImage = CommandExecutor[CopyImageCommand]
+type CopyImageCommand struct {
+	Source domain.Image
+	Target domain.Image
+}
+
+// NotifyRelease is a usecase that sends a release event to a notification sink, e.g. a chat webhook.
+type NotifyRelease = CommandExecutor[NotifyReleaseCommand]
+type NotifyReleaseCommand struct {
+	Event *domain.ReleaseEvent
+}
+
+// WriteVersions is a usecase that hands the versions of services to the following jobs of a CI pipeline.
+type WriteVersions = CommandExecutor[WriteVersionsCommand]
+type WriteVersionsCommand struct {
+	Versions []domain.ServiceVersion
+}
+
+// WriteFile is a usecase that replaces the content of a file of the repository, e.g. a manifest.
+type WriteFile = CommandExecutor[WriteFileCommand]
+type WriteFileCommand struct {
+	Name string
+	Data []byte
+}
+
+func (c WriteFileCommand) String() string {
+	return fmt.Sprintf("write %s (%d bytes)", c.Name, len(c.Data))
+}
+
+// CreateCommit is a usecase that commits files on HEAD.
+type CreateCommit = CommandExecutor[CreateCommitCommand]
+type CreateCommitCommand struct {
+	Message string
+	Files   []string
+}
+
+// ResetCommit is a usecase that moves HEAD back to a commit, e.g. to undo a release commit that could not be tagged.
+// The changes of Files are unstaged, the working tree and the other staged changes are kept.
+type ResetCommit = CommandExecutor[ResetCommitCommand]
+type ResetCommitCommand struct {
+	CommitId *domain.CommitId
+	Files    []string
+}
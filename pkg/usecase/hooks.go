@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// TagViolation is a pushed tag that breaks a rule of the pre-push hook.
+type TagViolation struct {
+	Tag    domain.GitTag
+	Reason string
+}
+
+func (v TagViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Tag, v.Reason)
+}
+
+// PrePushRules are the rules ValidatePushedTags checks.
+type PrePushRules struct {
+	// Services are the configured services, a tag starting with one of them must be a valid service tag.
+	Services []domain.ServiceName
+	// ProtectedBranches are the revisions the commits of service tags must be reachable from,
+	// no branch is checked when empty.
+	ProtectedBranches []string
+}
+
+// ValidatePushedTags checks the tags being pushed to remote.
+// A service tag must parse, must not be older than the latest tag of its service on the remote,
+// and must point at a commit of a protected branch.
+func ValidatePushedTags(
+	tags []domain.GitTag,
+	rules PrePushRules,
+	remoteList ListRemoteTagRefs,
+	finder CommitFinder,
+	ancestor IsAncestor,
+	remote *domain.RemoteAddr,
+) ([]TagViolation, error) {
+	violations := []TagViolation{}
+	serviceTags := map[domain.GitTag]*domain.ServiceTagWithSemVer{}
+	services := []domain.ServiceName{}
+	for _, tag := range tags {
+		serviceTag, err := tag.ToServiceTag()
+		if err != nil {
+			if service, ok := configuredServiceOf(tag, rules.Services); ok {
+				violations = append(violations, TagViolation{
+					Tag:    tag,
+					Reason: fmt.Sprintf("looks like a tag of %s but is not SERVICE-vMAJOR.MINOR.PATCH", service),
+				})
+			}
+			continue
+		}
+		serviceTags[tag] = serviceTag
+		services = append(services, serviceTag.Service)
+	}
+	if len(serviceTags) == 0 {
+		return violations, nil
+	}
+
+	remoteRefs, err := remoteList.Execute(ListRemoteTagRefsQuery{RemoteAddr: remote, Services: services})
+	if err != nil {
+		return nil, err
+	}
+	latest := map[domain.ServiceName]*domain.ServiceTagWithSemVer{}
+	for _, ref := range *remoteRefs {
+		serviceTag, err := ref.Tag.ToServiceTag()
+		if err != nil {
+			continue
+		}
+		if current, ok := latest[serviceTag.Service]; !ok || serviceTag.GreaterThan(current) {
+			latest[serviceTag.Service] = serviceTag
+		}
+	}
+
+	for _, tag := range tags {
+		serviceTag, ok := serviceTags[tag]
+		if !ok {
+			continue
+		}
+		if current, ok := latest[serviceTag.Service]; ok && serviceTag.LessThan(current) {
+			violations = append(violations, TagViolation{
+				Tag:    tag,
+				Reason: fmt.Sprintf("goes back from %s on %s", current.String(), remote.String()),
+			})
+		}
+		if len(rules.ProtectedBranches) == 0 {
+			continue
+		}
+		commitId, err := finder.Execute(FindCommitQuery{Tag: &tag})
+		if err != nil {
+			return nil, err
+		}
+		onBranch, err := isOnAnyBranch(ancestor, commitId, rules.ProtectedBranches)
+		if err != nil {
+			return nil, err
+		}
+		if !onBranch {
+			violations = append(violations, TagViolation{
+				Tag:    tag,
+				Reason: fmt.Sprintf("commit %s is not on %s", commitId.String(), strings.Join(rules.ProtectedBranches, " or ")),
+			})
+		}
+	}
+	return violations, nil
+}
+
+func isOnAnyBranch(ancestor IsAncestor, commitId *domain.CommitId, branches []string) (bool, error) {
+	for _, branch := range branches {
+		ok, err := ancestor.Execute(IsAncestorQuery{CommitId: commitId, Revision: branch})
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func configuredServiceOf(tag domain.GitTag, services []domain.ServiceName) (domain.ServiceName, bool) {
+	for _, service := range services {
+		if strings.HasPrefix(tag.String(), service.String()+"-") {
+			return service, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type StubCommitsByTag struct {
+	commits map[domain.GitTag]domain.CommitId
+}
+
+func (s *StubCommitsByTag) Execute(query usecase.FindCommitQuery) (*domain.CommitId, error) {
+	commitId := s.commits[*query.Tag]
+	return &commitId, nil
+}
+
+// StubBranches knows the commits reachable from each branch.
+type StubBranches struct {
+	commits map[string][]domain.CommitId
+}
+
+func (s *StubBranches) Execute(query usecase.IsAncestorQuery) (bool, error) {
+	for _, commitId := range s.commits[query.Revision] {
+		if commitId == *query.CommitId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestValidatePushedTags(t *testing.T) {
+	origin := domain.Origin
+	remoteList := &StubRemoteTagRefList{refs: map[domain.RemoteAddr][]domain.TagRef{
+		origin: {
+			{Tag: "service-a-v1.2.0", CommitId: "0000001"},
+			{Tag: "service-b-v0.1.0", CommitId: "0000001"},
+		},
+	}}
+	finder := &StubCommitsByTag{commits: map[domain.GitTag]domain.CommitId{
+		"service-a-v1.1.0": "0000001",
+		"service-a-v1.3.0": "0000002",
+		"service-b-v0.2.0": "feature",
+	}}
+	branches := &StubBranches{commits: map[string][]domain.CommitId{
+		"main":        {"0000001"},
+		"origin/main": {"0000001", "0000002"},
+	}}
+	rules := usecase.PrePushRules{
+		Services:          []domain.ServiceName{"service-a", "service-b"},
+		ProtectedBranches: []string{"main", "origin/main"},
+	}
+	tags := []domain.GitTag{"service-a-v1.1.0", "service-a-v1.3.0", "service-b-v0.2.0", "service-b-latest", "release"}
+
+	violations, err := usecase.ValidatePushedTags(tags, rules, remoteList, finder, branches, &origin)
+	if err != nil {
+		t.Fatalf("ValidatePushedTags() error = %v, want nil", err)
+	}
+	want := map[domain.GitTag]bool{
+		"service-a-v1.1.0": true,
+		"service-b-v0.2.0": true,
+		"service-b-latest": true,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("ValidatePushedTags() = %v, want violations of %v", violations, want)
+	}
+	for _, violation := range violations {
+		if !want[violation.Tag] {
+			t.Errorf("unexpected violation %s", violation)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// ImageRetag is the copy of the image of a commit to the tag of a service version.
+type ImageRetag struct {
+	Tag    *domain.ServiceTagWithSemVer
+	Source domain.Image
+	Target domain.Image
+}
+
+// MissingImagesError reports the images of a commit that are not in their registries.
+type MissingImagesError struct {
+	Images []domain.Image
+}
+
+func (e *MissingImagesError) Error() string {
+	images := make([]string, 0, len(e.Images))
+	for _, image := range e.Images {
+		images = append(images, image.String())
+	}
+	return fmt.Sprintf("images not found: %s", strings.Join(images, ", "))
+}
+
+// RetagServiceImages tags the image built for the commit of every tag with the version of the tag.
+// Every source image is checked first, nothing is copied when one of them is missing.
+func RetagServiceImages(tags []*domain.ServiceTagWithSemVer, commitId domain.CommitId, naming *domain.ImageNaming, exists ImageExists, copier CopyImage) ([]ImageRetag, error) {
+	retags := make([]ImageRetag, 0, len(tags))
+	missing := []domain.Image{}
+	for _, tag := range tags {
+		source, err := naming.Source(tag, commitId)
+		if err != nil {
+			return nil, err
+		}
+		target, err := naming.Target(tag, commitId)
+		if err != nil {
+			return nil, err
+		}
+		if source.Repository != target.Repository {
+			return nil, fmt.Errorf("can not copy %s to another repository %s", source, target)
+		}
+		ok, err := exists.Execute(ImageExistsQuery{Image: source})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", source, err)
+		}
+		if !ok {
+			missing = append(missing, source)
+		}
+		retags = append(retags, ImageRetag{Tag: tag, Source: source, Target: target})
+	}
+	if len(missing) > 0 {
+		return nil, &MissingImagesError{Images: missing}
+	}
+	for i, retag := range retags {
+		err := copier.Execute(CopyImageCommand{Source: retag.Source, Target: retag.Target})
+		if err != nil {
+			return retags[:i], fmt.Errorf("failed to copy %s to %s: %w", retag.Source, retag.Target, err)
+		}
+	}
+	return retags, nil
+}
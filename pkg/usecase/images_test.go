@@ -0,0 +1,69 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+// StubRegistry is a registry of Images recording the copies of StubImageCopier.
+type StubRegistry struct {
+	Images map[domain.Image]bool
+	Copies []usecase.CopyImageCommand
+}
+
+func (s *StubRegistry) Execute(query usecase.ImageExistsQuery) (bool, error) {
+	return s.Images[query.Image], nil
+}
+
+type StubImageCopier struct {
+	registry *StubRegistry
+}
+
+func (s *StubImageCopier) Execute(cmd usecase.CopyImageCommand) error {
+	s.registry.Copies = append(s.registry.Copies, cmd)
+	s.registry.Images[cmd.Target] = true
+	return nil
+}
+
+func TestRetagServiceImages(t *testing.T) {
+	naming := &domain.ImageNaming{Registry: "registry.example.com"}
+	registry := &StubRegistry{Images: map[domain.Image]bool{
+		{Repository: "registry.example.com/api", Tag: "0000001"}: true,
+		{Repository: "registry.example.com/web", Tag: "0000001"}: true,
+	}}
+	copier := &StubImageCopier{registry: registry}
+
+	retags, err := usecase.RetagServiceImages(*serviceTagsOf("api-v1.2.0", "web-v0.1.0"), "0000001", naming, registry, copier)
+	if err != nil {
+		t.Fatalf("RetagServiceImages() error = %v, want nil", err)
+	}
+	if len(retags) != 2 || len(registry.Copies) != 2 {
+		t.Fatalf("RetagServiceImages() = %v, copies %v, want 2", retags, registry.Copies)
+	}
+	want := domain.Image{Repository: "registry.example.com/api", Tag: "v1.2.0"}
+	if registry.Copies[0].Target != want {
+		t.Errorf("copy target = %s, want %s", registry.Copies[0].Target, want)
+	}
+}
+
+func TestRetagServiceImagesChecksEverySourceFirst(t *testing.T) {
+	naming := &domain.ImageNaming{Registry: "registry.example.com"}
+	registry := &StubRegistry{Images: map[domain.Image]bool{
+		{Repository: "registry.example.com/api", Tag: "0000001"}: true,
+	}}
+	copier := &StubImageCopier{registry: registry}
+
+	_, err := usecase.RetagServiceImages(*serviceTagsOf("api-v1.2.0", "web-v0.1.0"), "0000001", naming, registry, copier)
+	missing := &usecase.MissingImagesError{}
+	if !errors.As(err, &missing) {
+		t.Fatalf("RetagServiceImages() error = %v, want MissingImagesError", err)
+	}
+	if len(missing.Images) != 1 || missing.Images[0].String() != "registry.example.com/web:0000001" {
+		t.Errorf("missing = %v, want registry.example.com/web:0000001", missing.Images)
+	}
+	if len(registry.Copies) != 0 {
+		t.Errorf("copies = %v, want none when an image is missing", registry.Copies)
+	}
+}
@@ -7,24 +7,25 @@ type ServiceTagInfo struct {
 	CommitId *domain.CommitId
 }
 
-func ServiceTagsList(filter func(*domain.ServiceName) bool, list ListTags, finder CommitFinder) ([]*ServiceTagInfo, error) {
-	tags, err := list.Execute(ListTagsQuery{Filter: filter})
+// ServiceTagsList lists the service tags of services with their commits.
+// The tags of every service are listed when services is empty.
+func ServiceTagsList(services []domain.ServiceName, list ListTagRefs) ([]*ServiceTagInfo, error) {
+	refs, err := list.Execute(ListTagRefsQuery{Services: services})
 	if err != nil {
 		return nil, err
 	}
-	serviceTags := domain.FilterServiceTags(tags)
 
-	infos := make([]*ServiceTagInfo, 0, len(*serviceTags))
+	infos := make([]*ServiceTagInfo, 0, len(*refs))
 
-	for _, tag := range *serviceTags {
-		gitTag := tag.ToGitTag()
-		commitId, err := finder.Execute(FindCommitQuery{Tag: &gitTag})
+	for _, ref := range *refs {
+		tag, err := ref.Tag.ToServiceTag()
 		if err != nil {
-			return nil, err
+			continue
 		}
+		commitId := ref.CommitId
 		infos = append(infos, &ServiceTagInfo{
 			Tag:      tag,
-			CommitId: commitId,
+			CommitId: &commitId,
 		})
 	}
 	return infos, nil
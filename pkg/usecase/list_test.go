@@ -0,0 +1,33 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestServiceTagsList(t *testing.T) {
+	stub := &StubTagRefList{
+		refs: []domain.TagRef{
+			{Tag: "service-a-v1.2.3", CommitId: "commit1"},
+			{Tag: "service-a-gateway-v0.1.0", CommitId: "commit1"},
+			{Tag: "service-b-1.0.0", CommitId: "commit2"},
+			{Tag: "release", CommitId: "commit2"},
+		},
+	}
+	got, err := usecase.ServiceTagsList([]domain.ServiceName{"service-a"}, stub)
+	if err != nil {
+		t.Fatalf("ServiceTagsList() error = %v, want nil", err)
+	}
+	commit1 := domain.CommitId("commit1")
+	want := []*usecase.ServiceTagInfo{
+		{Tag: domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 2, 3)), CommitId: &commit1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ServiceTagsList() = %v, want %v", got, want)
+	}
+	if len(stub.Queries) != 1 {
+		t.Errorf("ServiceTagsList() queried %d times, want 1", len(stub.Queries))
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"msgtm/pkg/domain"
+)
+
+// NotifyReleases sends a release event of every tag to the notifier.
+// remote is the remote the tags were pushed to, empty when they are only local.
+// A failed event does not stop the others, the failures are joined.
+func NotifyReleases(notifier NotifyRelease, action domain.ReleaseAction, remote string, tags []*domain.ServiceTagWithSemVer, list ListTagRefs, commits ListCommits) error {
+	releases, err := Releases(tags, list, commits)
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, release := range releases {
+		err := notifier.Execute(NotifyReleaseCommand{Event: domain.NewReleaseEvent(action, remote, release)})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", release.Tag.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Notifiers sends every event to all of the sinks.
+// A failed sink does not stop the others, the failures are joined.
+type Notifiers []NotifyRelease
+
+func (n Notifiers) Execute(cmd NotifyReleaseCommand) error {
+	errs := []error{}
+	for _, notifier := range n {
+		if err := notifier.Execute(cmd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type MockNotifier struct {
+	Events []*domain.ReleaseEvent
+	Fails  bool
+}
+
+func (m *MockNotifier) Execute(cmd usecase.NotifyReleaseCommand) error {
+	if m.Fails {
+		return errors.New("unavailable")
+	}
+	m.Events = append(m.Events, cmd.Event)
+	return nil
+}
+
+func TestNotifyReleases(t *testing.T) {
+	log := &StubCommitLog{Commits: []domain.Commit{
+		{Id: "0000001", Subject: "init"},
+		{Id: "0000002", Subject: "fix api"},
+	}}
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "api-v1.0.0", CommitId: "0000001"},
+		{Tag: "api-v1.0.1", CommitId: "0000002"},
+	}}
+	broken := &MockNotifier{Fails: true}
+	notifier := &MockNotifier{}
+
+	err := usecase.NotifyReleases(usecase.Notifiers{broken, notifier}, domain.ReleasePushed, "origin", *serviceTagsOf("api-v1.0.1"), list, log)
+	if err == nil {
+		t.Error("NotifyReleases() error = nil, want the failure of the broken sink")
+	}
+	if len(notifier.Events) != 1 {
+		t.Fatalf("notified %d events, want 1 in spite of the broken sink", len(notifier.Events))
+	}
+	event := notifier.Events[0]
+	if event.Action != domain.ReleasePushed || event.Remote != "origin" || event.Service != "api" {
+		t.Errorf("event = %+v, want push of api to origin", event)
+	}
+	if event.Version != "v1.0.1" || event.PreviousVersion != "v1.0.0" || event.Commit != "0000002" {
+		t.Errorf("event = %s from %s at %s, want v1.0.1 from v1.0.0 at 0000002", event.Version, event.PreviousVersion, event.Commit)
+	}
+	if len(event.Changelog) != 1 || event.Changelog[0].Subject != "fix api" {
+		t.Errorf("changelog = %v, want fix api", event.Changelog)
+	}
+}
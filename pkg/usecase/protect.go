@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+	"time"
+)
+
+// ProtectionPolicy limits which service tags can be deleted.
+type ProtectionPolicy struct {
+	// Services are the services whose tags can never be deleted.
+	Services []domain.ServiceName
+	// LockedAfter is the age after which annotated tags can not be deleted, no tag is locked when zero.
+	// Lightweight tags are never locked, git does not record when they were created.
+	LockedAfter time.Duration
+}
+
+// BlockedTag is a tag that a rule of the ProtectionPolicy refused to delete.
+type BlockedTag struct {
+	Tag  *domain.ServiceTagWithSemVer
+	Rule string
+}
+
+// ProtectedTagsError is returned when some of the tags are protected, nothing is deleted then.
+type ProtectedTagsError struct {
+	Blocked []BlockedTag
+}
+
+func (e *ProtectedTagsError) Error() string {
+	messages := make([]string, 0, len(e.Blocked))
+	for _, blocked := range e.Blocked {
+		messages = append(messages, fmt.Sprintf("  %s: %s", blocked.Tag.String(), blocked.Rule))
+	}
+	return "refused to delete protected service tags:\n" + strings.Join(messages, "\n")
+}
+
+// ProtectedDestroyer deletes tags through Destroyer only when none of them is protected by Policy.
+type ProtectedDestroyer struct {
+	Destroyer DestroyServiceTags
+	Policy    ProtectionPolicy
+	List      ListTagRefs
+	// Now is time.Now when nil.
+	Now func() time.Time
+}
+
+func (p *ProtectedDestroyer) Execute(cmd DestroyServiceTagsCommand) error {
+	blocked, err := p.blocked(*cmd.Tags)
+	if err != nil {
+		return err
+	}
+	if len(blocked) > 0 {
+		return &ProtectedTagsError{Blocked: blocked}
+	}
+	return p.Destroyer.Execute(cmd)
+}
+
+func (p *ProtectedDestroyer) blocked(tags []*domain.ServiceTagWithSemVer) ([]BlockedTag, error) {
+	created := map[domain.GitTag]time.Time{}
+	if p.Policy.LockedAfter > 0 && len(tags) > 0 {
+		services := []domain.ServiceName{}
+		for _, tag := range tags {
+			services = append(services, tag.Service)
+		}
+		refs, err := p.List.Execute(ListTagRefsQuery{Services: services})
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range *refs {
+			// the date of a lightweight tag is the date of its commit, not of the tag
+			if ref.Type == domain.LightweightTag {
+				continue
+			}
+			created[ref.Tag] = ref.TaggerDate
+		}
+	}
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	blocked := []BlockedTag{}
+	for _, tag := range tags {
+		if containsService(p.Policy.Services, tag.Service) {
+			blocked = append(blocked, BlockedTag{Tag: tag, Rule: fmt.Sprintf("%s is a protected service", tag.Service)})
+			continue
+		}
+		date, ok := created[tag.ToGitTag()]
+		if !ok || date.IsZero() {
+			continue
+		}
+		if age := now().Sub(date); age > p.Policy.LockedAfter {
+			blocked = append(blocked, BlockedTag{
+				Tag:  tag,
+				Rule: fmt.Sprintf("created %s ago, tags older than %s are locked", age.Truncate(time.Minute), p.Policy.LockedAfter),
+			})
+		}
+	}
+	return blocked, nil
+}
+
+func containsService(services []domain.ServiceName, service domain.ServiceName) bool {
+	for _, s := range services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+	"time"
+)
+
+func TestProtectedDestroyerBlocksProtectedTags(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.0.0", TaggerDate: now.Add(-time.Hour)},
+		{Tag: "service-b-v1.0.0", TaggerDate: now.Add(-10 * 24 * time.Hour)},
+		{Tag: "service-c-v1.0.0", TaggerDate: now.Add(-time.Hour)},
+	}}
+	destroyer := &MockDestroyer{}
+	sut := &usecase.ProtectedDestroyer{
+		Destroyer: destroyer,
+		Policy: usecase.ProtectionPolicy{
+			Services:    []domain.ServiceName{"service-c"},
+			LockedAfter: 72 * time.Hour,
+		},
+		List: list,
+		Now:  func() time.Time { return now },
+	}
+
+	err := sut.Execute(usecase.DestroyServiceTagsCommand{Tags: serviceTagsOf("service-a-v1.0.0", "service-b-v1.0.0", "service-c-v1.0.0")})
+	var protectedErr *usecase.ProtectedTagsError
+	if !errors.As(err, &protectedErr) {
+		t.Fatalf("Execute() error = %v, want ProtectedTagsError", err)
+	}
+	blocked := map[string]bool{}
+	for _, b := range protectedErr.Blocked {
+		blocked[b.Tag.String()] = true
+	}
+	if len(blocked) != 2 || !blocked["service-b-v1.0.0"] || !blocked["service-c-v1.0.0"] {
+		t.Errorf("Blocked = %v, want service-b-v1.0.0 and service-c-v1.0.0", protectedErr.Blocked)
+	}
+	if destroyer.Destroyed != nil {
+		t.Errorf("Destroyed = %v, want nothing", *destroyer.Destroyed)
+	}
+
+	err = sut.Execute(usecase.DestroyServiceTagsCommand{Tags: serviceTagsOf("service-a-v1.0.0")})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil", err)
+	}
+	if destroyer.Destroyed == nil || len(*destroyer.Destroyed) != 1 {
+		t.Errorf("Destroyed = %v, want service-a-v1.0.0", destroyer.Destroyed)
+	}
+}
+
+func TestProtectedDestroyerDoesNotLockLightweightTags(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	// the date of a lightweight tag is the date of its old commit
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.0.0", Type: domain.LightweightTag, TaggerDate: now.Add(-10 * 24 * time.Hour)},
+		{Tag: "service-b-v1.0.0", Type: domain.AnnotatedTag, TaggerDate: now.Add(-10 * 24 * time.Hour)},
+	}}
+	destroyer := &MockDestroyer{}
+	sut := &usecase.ProtectedDestroyer{
+		Destroyer: destroyer,
+		Policy:    usecase.ProtectionPolicy{LockedAfter: 72 * time.Hour},
+		List:      list,
+		Now:       func() time.Time { return now },
+	}
+
+	err := sut.Execute(usecase.DestroyServiceTagsCommand{Tags: serviceTagsOf("service-a-v1.0.0")})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want nil for a lightweight tag", err)
+	}
+	err = sut.Execute(usecase.DestroyServiceTagsCommand{Tags: serviceTagsOf("service-b-v1.0.0")})
+	var protectedErr *usecase.ProtectedTagsError
+	if !errors.As(err, &protectedErr) {
+		t.Fatalf("Execute() error = %v, want ProtectedTagsError for an old annotated tag", err)
+	}
+}
+
+func serviceTagsOf(tags ...domain.GitTag) *[]*domain.ServiceTagWithSemVer {
+	return domain.FilterServiceTags(&tags)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// ConflictStrategy decides which side wins when a tag points at different commits locally and on the remote.
+type ConflictStrategy string
+
+const (
+	PreferRemote   ConflictStrategy = "remote"
+	PreferLocal    ConflictStrategy = "local"
+	FailOnConflict ConflictStrategy = "fail"
+)
+
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(s); strategy {
+	case PreferRemote, PreferLocal, FailOnConflict:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown conflict strategy: %s, strategy should be %s, %s or %s", s, PreferRemote, PreferLocal, FailOnConflict)
+}
+
+type PullResult struct {
+	Fetched  []domain.TagRef
+	UpToDate []domain.TagRef
+	// Overwritten are the conflicts resolved by the remote tag.
+	Overwritten []domain.TagConflict
+	// Kept are the conflicts resolved by the local tag.
+	Kept []domain.TagConflict
+	// Unresolved are the conflicts that made the pull fail.
+	Unresolved []domain.TagConflict
+}
+
+type ConflictError struct {
+	Conflicts []domain.TagConflict
+}
+
+func (e *ConflictError) Error() string {
+	tags := []string{}
+	for _, conflict := range e.Conflicts {
+		tags = append(tags, conflict.Tag.String())
+	}
+	return fmt.Sprintf("tags point at different commits locally and on the remote: %s", strings.Join(tags, ", "))
+}
+
+// PullServiceTags fetches the service tags of services from remote.
+// The service tags of every service are fetched when services is empty.
+// Conflicting tags are resolved by strategy, and nothing is fetched when
+// strategy is FailOnConflict and a conflict exists.
+func PullServiceTags(
+	local ListTagRefs,
+	remoteList ListRemoteTagRefs,
+	fetcher FetchTags,
+	remote *domain.RemoteAddr,
+	services []domain.ServiceName,
+	strategy ConflictStrategy,
+) (*PullResult, error) {
+	remoteRefs, err := remoteList.Execute(ListRemoteTagRefsQuery{
+		RemoteAddr: remote,
+		Services:   services,
+	})
+	if err != nil {
+		return nil, err
+	}
+	localRefs, err := local.Execute(ListTagRefsQuery{Services: services})
+	if err != nil {
+		return nil, err
+	}
+	serviceRefs := []domain.TagRef{}
+	for _, ref := range *remoteRefs {
+		if ref.IsServiceTagOf(services) {
+			serviceRefs = append(serviceRefs, ref)
+		}
+	}
+	reconciliation := domain.ReconcileTags(*localRefs, serviceRefs)
+	result := &PullResult{
+		Fetched:     reconciliation.New,
+		UpToDate:    reconciliation.UpToDate,
+		Overwritten: []domain.TagConflict{},
+		Kept:        []domain.TagConflict{},
+		Unresolved:  []domain.TagConflict{},
+	}
+	switch strategy {
+	case FailOnConflict:
+		if len(reconciliation.Conflicts) > 0 {
+			result.Fetched = []domain.TagRef{}
+			result.Unresolved = reconciliation.Conflicts
+			return result, &ConflictError{Conflicts: reconciliation.Conflicts}
+		}
+	case PreferLocal:
+		result.Kept = reconciliation.Conflicts
+	case PreferRemote:
+		result.Overwritten = reconciliation.Conflicts
+	}
+
+	tags := []domain.GitTag{}
+	for _, ref := range result.Fetched {
+		tags = append(tags, ref.Tag)
+	}
+	if len(tags) > 0 {
+		err = fetcher.Execute(FetchTagsCommand{RemoteAddr: remote, Tags: &tags})
+		if err != nil {
+			return nil, err
+		}
+	}
+	overwrites := []domain.GitTag{}
+	for _, conflict := range result.Overwritten {
+		overwrites = append(overwrites, conflict.Tag)
+	}
+	if len(overwrites) > 0 {
+		err = fetcher.Execute(FetchTagsCommand{RemoteAddr: remote, Tags: &overwrites, Force: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
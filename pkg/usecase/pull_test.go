@@ -0,0 +1,96 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestPullServiceTags(t *testing.T) {
+	local := &StubTagRefList{
+		refs: []domain.TagRef{
+			{Tag: "service-a-v1.0.0", CommitId: "commit1"},
+			{Tag: "service-a-v1.1.0", CommitId: "stale"},
+			{Tag: "service-b-v0.1.0", CommitId: "local-only"},
+		},
+	}
+	remoteRefs := &StubRemoteTagRefList{
+		refs: map[domain.RemoteAddr][]domain.TagRef{
+			"origin": {
+				{Tag: "service-a-v1.0.0", CommitId: "commit1"},
+				{Tag: "service-a-v1.1.0", CommitId: "commit2"},
+				{Tag: "service-a-v1.2.0", CommitId: "commit3"},
+				{Tag: "release", CommitId: "commit3"},
+			},
+		},
+	}
+	conflicts := []domain.TagConflict{{Tag: "service-a-v1.1.0", Local: "stale", Remote: "commit2"}}
+	origin := domain.Origin
+	tests := []struct {
+		name         string
+		strategy     usecase.ConflictStrategy
+		wantFetches  []usecase.FetchTagsCommand
+		wantResult   *usecase.PullResult
+		wantConflict bool
+	}{
+		{
+			name:     "fail does not fetch anything",
+			strategy: usecase.FailOnConflict,
+			wantResult: &usecase.PullResult{
+				Fetched:     []domain.TagRef{},
+				UpToDate:    []domain.TagRef{{Tag: "service-a-v1.0.0", CommitId: "commit1"}},
+				Overwritten: []domain.TagConflict{},
+				Kept:        []domain.TagConflict{},
+				Unresolved:  conflicts,
+			},
+			wantConflict: true,
+		},
+		{
+			name:     "local keeps the local tag",
+			strategy: usecase.PreferLocal,
+			wantFetches: []usecase.FetchTagsCommand{
+				{RemoteAddr: &origin, Tags: &[]domain.GitTag{"service-a-v1.2.0"}},
+			},
+			wantResult: &usecase.PullResult{
+				Fetched:     []domain.TagRef{{Tag: "service-a-v1.2.0", CommitId: "commit3"}},
+				UpToDate:    []domain.TagRef{{Tag: "service-a-v1.0.0", CommitId: "commit1"}},
+				Overwritten: []domain.TagConflict{},
+				Kept:        conflicts,
+				Unresolved:  []domain.TagConflict{},
+			},
+		},
+		{
+			name:     "remote overwrites the local tag",
+			strategy: usecase.PreferRemote,
+			wantFetches: []usecase.FetchTagsCommand{
+				{RemoteAddr: &origin, Tags: &[]domain.GitTag{"service-a-v1.2.0"}},
+				{RemoteAddr: &origin, Tags: &[]domain.GitTag{"service-a-v1.1.0"}, Force: true},
+			},
+			wantResult: &usecase.PullResult{
+				Fetched:     []domain.TagRef{{Tag: "service-a-v1.2.0", CommitId: "commit3"}},
+				UpToDate:    []domain.TagRef{{Tag: "service-a-v1.0.0", CommitId: "commit1"}},
+				Overwritten: conflicts,
+				Kept:        []domain.TagConflict{},
+				Unresolved:  []domain.TagConflict{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &MockFetcher{}
+			result, err := usecase.PullServiceTags(local, remoteRefs, fetcher, &origin, nil, tt.strategy)
+			var conflictErr *usecase.ConflictError
+			if tt.wantConflict != errors.As(err, &conflictErr) {
+				t.Fatalf("PullServiceTags() error = %v, want conflict %v", err, tt.wantConflict)
+			}
+			if !reflect.DeepEqual(result, tt.wantResult) {
+				t.Errorf("PullServiceTags() = %+v, want %+v", result, tt.wantResult)
+			}
+			if !reflect.DeepEqual(fetcher.Commands, tt.wantFetches) {
+				t.Errorf("fetches = %+v, want %+v", fetcher.Commands, tt.wantFetches)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+)
+
+type PushStatus string
+
+const (
+	Pushed   PushStatus = "pushed"
+	UpToDate PushStatus = "up to date"
+	Rejected PushStatus = "rejected"
+)
+
+type TagPushOutcome struct {
+	Remote domain.RemoteAddr
+	Tag    *domain.ServiceTagWithSemVer
+	Status PushStatus
+	// Reason explains why the tag was rejected.
+	Reason string
+}
+
+type PushReport struct {
+	Outcomes []TagPushOutcome
+}
+
+func (r *PushReport) Rejected() []TagPushOutcome {
+	rejected := []TagPushOutcome{}
+	for _, outcome := range r.Outcomes {
+		if outcome.Status == Rejected {
+			rejected = append(rejected, outcome)
+		}
+	}
+	return rejected
+}
+
+type PushRejectedError struct {
+	Rejected []TagPushOutcome
+}
+
+func (e *PushRejectedError) Error() string {
+	return fmt.Sprintf("%d service tags were rejected", len(e.Rejected))
+}
+
+// PushAllToRemotes pushes the service tags of the commit to every remote.
+// A failure on one remote does not stop the push to the others, and the
+// outcome of every tag on every remote is reported.
+// Tags that already exist on a remote at the same commit are not pushed again,
+// and tags that exist at another commit are rejected without being pushed.
+// With atomic, a remote gets either all of the pushed tags or none of them.
+func PushAllToRemotes(
+	commitGetter CommitTagGetter,
+	local ListTagRefs,
+	remoteList ListRemoteTagRefs,
+	pusher CommitPusher,
+	remotes []*domain.RemoteAddr,
+	commitId *domain.CommitId,
+	atomic bool,
+) (*PushReport, error) {
+	tags, err := commitGetter.Execute(GetCommitTagQuery{CommitId: commitId})
+	if err != nil {
+		return nil, err
+	}
+	serviceTags := domain.FilterServiceTags(tags)
+	services := []domain.ServiceName{}
+	for _, tag := range *serviceTags {
+		services = append(services, tag.Service)
+	}
+	report := &PushReport{Outcomes: []TagPushOutcome{}}
+	if len(*serviceTags) == 0 {
+		return report, nil
+	}
+	localRefs, err := local.Execute(ListTagRefsQuery{Services: services})
+	if err != nil {
+		return nil, err
+	}
+	localCommits := commitsByTag(*localRefs)
+
+	for _, remote := range remotes {
+		outcomes := pushToRemote(remoteList, pusher, remote, services, *serviceTags, localCommits, atomic)
+		report.Outcomes = append(report.Outcomes, outcomes...)
+	}
+	if rejected := report.Rejected(); len(rejected) > 0 {
+		return report, &PushRejectedError{Rejected: rejected}
+	}
+	return report, nil
+}
+
+func pushToRemote(
+	remoteList ListRemoteTagRefs,
+	pusher CommitPusher,
+	remote *domain.RemoteAddr,
+	services []domain.ServiceName,
+	serviceTags []*domain.ServiceTagWithSemVer,
+	localCommits map[domain.GitTag]domain.CommitId,
+	atomic bool,
+) []TagPushOutcome {
+	outcomes := []TagPushOutcome{}
+	rejectAll := func(tags []*domain.ServiceTagWithSemVer, reason string) []TagPushOutcome {
+		for _, tag := range tags {
+			outcomes = append(outcomes, TagPushOutcome{Remote: *remote, Tag: tag, Status: Rejected, Reason: reason})
+		}
+		return outcomes
+	}
+	remoteRefs, err := remoteList.Execute(ListRemoteTagRefsQuery{RemoteAddr: remote, Services: services})
+	if err != nil {
+		return rejectAll(serviceTags, err.Error())
+	}
+	remoteCommits := commitsByTag(*remoteRefs)
+
+	targets := []*domain.ServiceTagWithSemVer{}
+	for _, tag := range serviceTags {
+		remoteCommit, ok := remoteCommits[tag.ToGitTag()]
+		if !ok {
+			targets = append(targets, tag)
+			continue
+		}
+		if remoteCommit == localCommits[tag.ToGitTag()] {
+			outcomes = append(outcomes, TagPushOutcome{Remote: *remote, Tag: tag, Status: UpToDate})
+			continue
+		}
+		outcomes = append(outcomes, TagPushOutcome{
+			Remote: *remote,
+			Tag:    tag,
+			Status: Rejected,
+			Reason: fmt.Sprintf("already exists at %s", remoteCommit.String()),
+		})
+	}
+	if len(targets) == 0 {
+		return outcomes
+	}
+
+	err = pusher.Execute(CommitPushCommand{RemoteAddr: remote, Tags: &targets, Atomic: atomic})
+	if err == nil {
+		for _, tag := range targets {
+			outcomes = append(outcomes, TagPushOutcome{Remote: *remote, Tag: tag, Status: Pushed})
+		}
+		return outcomes
+	}
+	// a push that is not atomic may have updated some of the tags
+	pushErr := err
+	remoteRefs, err = remoteList.Execute(ListRemoteTagRefsQuery{RemoteAddr: remote, Services: services})
+	if err != nil {
+		return rejectAll(targets, pushErr.Error())
+	}
+	remoteCommits = commitsByTag(*remoteRefs)
+	for _, tag := range targets {
+		if remoteCommit, ok := remoteCommits[tag.ToGitTag()]; ok && remoteCommit == localCommits[tag.ToGitTag()] {
+			outcomes = append(outcomes, TagPushOutcome{Remote: *remote, Tag: tag, Status: Pushed})
+			continue
+		}
+		outcomes = append(outcomes, TagPushOutcome{Remote: *remote, Tag: tag, Status: Rejected, Reason: pushErr.Error()})
+	}
+	return outcomes
+}
+
+func commitsByTag(refs []domain.TagRef) map[domain.GitTag]domain.CommitId {
+	commits := map[domain.GitTag]domain.CommitId{}
+	for _, ref := range refs {
+		commits[ref.Tag] = ref.CommitId
+	}
+	return commits
+}
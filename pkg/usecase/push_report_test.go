@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestPushAllToRemotes(t *testing.T) {
+	commitGetter := &StubCommitGetter{
+		commitId: domain.HEAD,
+		tags:     []domain.GitTag{"service-a-v1.0.0", "service-b-v1.0.0", "service-c-v1.0.0"},
+	}
+	local := &StubTagRefList{
+		refs: []domain.TagRef{
+			{Tag: "service-a-v1.0.0", CommitId: "commit1"},
+			{Tag: "service-b-v1.0.0", CommitId: "commit1"},
+			{Tag: "service-c-v1.0.0", CommitId: "commit1"},
+		},
+	}
+	remote := &StubRemote{
+		refs: map[domain.RemoteAddr][]domain.TagRef{
+			"origin": {{Tag: "service-a-v1.0.0", CommitId: "commit1"}},
+			"mirror": {{Tag: "service-b-v1.0.0", CommitId: "other"}},
+		},
+		commits: map[domain.GitTag]domain.CommitId{
+			"service-a-v1.0.0": "commit1",
+			"service-b-v1.0.0": "commit1",
+			"service-c-v1.0.0": "commit1",
+		},
+		Rejects: map[domain.RemoteAddr]domain.GitTag{"backup": "service-c-v1.0.0"},
+	}
+	remoteList := &StubRemoteTagRefList{refs: remote.refs}
+	origin, mirror, backup := domain.RemoteAddr("origin"), domain.RemoteAddr("mirror"), domain.RemoteAddr("backup")
+	h := domain.HEAD
+
+	report, err := usecase.PushAllToRemotes(commitGetter, local, remoteList, remote, []*domain.RemoteAddr{&origin, &mirror, &backup}, &h, false)
+	var rejectedErr *usecase.PushRejectedError
+	if !errors.As(err, &rejectedErr) || len(rejectedErr.Rejected) != 2 {
+		t.Fatalf("PushAllToRemotes() error = %v, want 2 rejected tags", err)
+	}
+	tagA := domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 0, 0))
+	tagB := domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 0, 0))
+	tagC := domain.NewServiceTagWithSemVer("service-c", domain.NewSemVer(1, 0, 0))
+	want := []usecase.TagPushOutcome{
+		{Remote: origin, Tag: tagA, Status: usecase.UpToDate},
+		{Remote: origin, Tag: tagB, Status: usecase.Pushed},
+		{Remote: origin, Tag: tagC, Status: usecase.Pushed},
+		{Remote: mirror, Tag: tagB, Status: usecase.Rejected, Reason: "already exists at other"},
+		{Remote: mirror, Tag: tagA, Status: usecase.Pushed},
+		{Remote: mirror, Tag: tagC, Status: usecase.Pushed},
+		{Remote: backup, Tag: tagA, Status: usecase.Pushed},
+		{Remote: backup, Tag: tagB, Status: usecase.Pushed},
+		{Remote: backup, Tag: tagC, Status: usecase.Rejected, Reason: "failed to push some refs to backup"},
+	}
+	if !reflect.DeepEqual(report.Outcomes, want) {
+		t.Errorf("PushAllToRemotes() = %+v, want %+v", report.Outcomes, want)
+	}
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"msgtm/pkg/domain"
+)
+
+// Releases builds the releases of tags, the changelog of a release
+// lists the commits since the highest tag of the service below it.
+func Releases(tags []*domain.ServiceTagWithSemVer, list ListTagRefs, commits ListCommits) ([]*domain.Release, error) {
+	services := []domain.ServiceName{}
+	for _, tag := range tags {
+		services = append(services, tag.Service)
+	}
+	infos, err := ServiceTagsList(services, list)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]*domain.Release, 0, len(tags))
+	for _, tag := range tags {
+		var current, previous *ServiceTagInfo
+		for _, info := range infos {
+			if info.Tag.Service != tag.Service {
+				continue
+			}
+			if info.Tag.Equal(tag) {
+				current = info
+			}
+			if info.Tag.LessThan(tag) && (previous == nil || info.Tag.GreaterThan(previous.Tag)) {
+				previous = info
+			}
+		}
+		if current == nil {
+			return nil, fmt.Errorf("tag %s not found", tag.String())
+		}
+		release := &domain.Release{Tag: tag, CommitId: *current.CommitId}
+		query := ListCommitsQuery{To: current.CommitId}
+		if previous != nil {
+			release.Previous = previous.Tag
+			query.From = previous.CommitId
+		}
+		changelog, err := commits.Execute(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the commits of %s: %w", tag.String(), err)
+		}
+		release.Changelog = *changelog
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// PublishReleases publishes the releases of tags.
+// A failed release does not stop the others, the failures are joined.
+func PublishReleases(publisher PublishRelease, tags []*domain.ServiceTagWithSemVer, list ListTagRefs, commits ListCommits) error {
+	releases, err := Releases(tags, list, commits)
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, release := range releases {
+		err := publisher.Execute(PublishReleaseCommand{Release: release})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish %s: %w", release.Tag.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
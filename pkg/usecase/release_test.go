@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+// StubCommitLog is a linear history, Commits lists the commits oldest first.
+type StubCommitLog struct {
+	Commits []domain.Commit
+}
+
+func (s *StubCommitLog) Execute(query usecase.ListCommitsQuery) (*[]domain.Commit, error) {
+	result := []domain.Commit{}
+	listing := false
+	for i := len(s.Commits) - 1; i >= 0; i-- {
+		commit := s.Commits[i]
+		if commit.Id == *query.To {
+			listing = true
+		}
+		if query.From != nil && commit.Id == *query.From {
+			break
+		}
+		if listing {
+			result = append(result, commit)
+		}
+	}
+	return &result, nil
+}
+
+type MockPublisher struct {
+	Published []usecase.PublishReleaseCommand
+	Rejects   domain.GitTag
+}
+
+func (m *MockPublisher) Execute(cmd usecase.PublishReleaseCommand) error {
+	if cmd.Release.Tag.ToGitTag() == m.Rejects {
+		return errors.New("already exists")
+	}
+	m.Published = append(m.Published, cmd)
+	return nil
+}
+
+func TestPublishReleases(t *testing.T) {
+	log := &StubCommitLog{Commits: []domain.Commit{
+		{Id: "0000001", Subject: "init"},
+		{Id: "0000002", Subject: "add api"},
+		{Id: "0000003", Subject: "fix api"},
+	}}
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "api-v1.0.0", CommitId: "0000001"},
+		{Tag: "api-v1.1.0", CommitId: "0000003"},
+		{Tag: "api-v0.9.0", CommitId: "0000001"},
+		{Tag: "web-v0.1.0", CommitId: "0000002"},
+	}}
+	publisher := &MockPublisher{}
+	tags := *serviceTagsOf("api-v1.1.0", "web-v0.1.0")
+
+	err := usecase.PublishReleases(publisher, tags, list, log)
+	if err != nil {
+		t.Fatalf("PublishReleases() error = %v, want nil", err)
+	}
+	if len(publisher.Published) != 2 {
+		t.Fatalf("published %d releases, want 2", len(publisher.Published))
+	}
+	api := publisher.Published[0].Release
+	if api.Previous == nil || api.Previous.String() != "api-v1.0.0" {
+		t.Errorf("previous of api-v1.1.0 = %v, want api-v1.0.0", api.Previous)
+	}
+	want := []domain.Commit{{Id: "0000003", Subject: "fix api"}, {Id: "0000002", Subject: "add api"}}
+	if !reflect.DeepEqual(api.Changelog, want) {
+		t.Errorf("changelog of api-v1.1.0 = %v, want %v", api.Changelog, want)
+	}
+	web := publisher.Published[1].Release
+	if web.Previous != nil || len(web.Changelog) != 2 {
+		t.Errorf("first release of web = previous %v, %d commits, want nil, 2", web.Previous, len(web.Changelog))
+	}
+}
+
+func TestPublishReleasesContinuesAfterFailure(t *testing.T) {
+	log := &StubCommitLog{Commits: []domain.Commit{{Id: "0000001", Subject: "init"}}}
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "api-v1.0.0", CommitId: "0000001"},
+		{Tag: "web-v1.0.0", CommitId: "0000001"},
+	}}
+	publisher := &MockPublisher{Rejects: "api-v1.0.0"}
+
+	err := usecase.PublishReleases(publisher, *serviceTagsOf("api-v1.0.0", "web-v1.0.0"), list, log)
+	if err == nil {
+		t.Fatal("PublishReleases() error = nil, want the failure of api-v1.0.0")
+	}
+	if len(publisher.Published) != 1 || publisher.Published[0].Release.Tag.String() != "web-v1.0.0" {
+		t.Errorf("published = %v, want web-v1.0.0", publisher.Published)
+	}
+
+	err = usecase.PublishReleases(publisher, *serviceTagsOf("api-v2.0.0"), list, log)
+	if err == nil {
+		t.Error("PublishReleases() of a missing tag error = nil, want an error")
+	}
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import "msgtm/pkg/domain"
+
+type remoteTagRefs struct {
+	list   ListRemoteTagRefs
+	remote *domain.RemoteAddr
+}
+
+// RemoteTagRefs lets the usecases that read local tags read the tags of remote instead.
+func RemoteTagRefs(list ListRemoteTagRefs, remote *domain.RemoteAddr) ListTagRefs {
+	return &remoteTagRefs{
+		list:   list,
+		remote: remote,
+	}
+}
+
+func (r *remoteTagRefs) Execute(query ListTagRefsQuery) (*[]domain.TagRef, error) {
+	return r.list.Execute(ListRemoteTagRefsQuery{
+		RemoteAddr: r.remote,
+		Services:   query.Services,
+	})
+}
+
+type tagNames struct {
+	list ListTagRefs
+}
+
+// TagNames adapts ListTagRefs to ListTags, for the usecases that only need tag names.
+func TagNames(list ListTagRefs) ListTags {
+	return &tagNames{list: list}
+}
+
+func (t *tagNames) Execute(query ListTagsQuery) (*[]domain.GitTag, error) {
+	refs, err := t.list.Execute(ListTagRefsQuery{})
+	if err != nil {
+		return nil, err
+	}
+	tags := []domain.GitTag{}
+	for _, ref := range *refs {
+		serviceTag, err := ref.Tag.ToServiceTag()
+		if err != nil {
+			continue
+		}
+		if query.Filter(&serviceTag.Service) {
+			tags = append(tags, ref.Tag)
+		}
+	}
+	return &tags, nil
+}
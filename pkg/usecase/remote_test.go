@@ -0,0 +1,39 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestVersionUpFromRemoteTags(t *testing.T) {
+	stub := &StubRemoteTagRefList{
+		refs: map[domain.RemoteAddr][]domain.TagRef{
+			"origin": {
+				{Tag: "service-a-v1.2.3", CommitId: "commit1"},
+				{Tag: "service-b-v0.1.0", CommitId: "commit1"},
+				{Tag: "release", CommitId: "commit1"},
+			},
+		},
+	}
+	origin := domain.Origin
+	list := usecase.TagNames(usecase.RemoteTagRefs(stub, &origin))
+	mockRegister := &MockRegister{}
+	h := domain.HEAD
+	err := usecase.VersionUpAllServiceTags(list, mockRegister, domain.PatchUpAll, &h)
+	if err != nil {
+		t.Fatalf("VersionUpAllServiceTags() error = %v, want nil", err)
+	}
+	expected := []*domain.ServiceTagWithSemVer{
+		domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 2, 4)),
+		domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(0, 1, 1)),
+	}
+	if !cmpArrayContent(*mockRegister.AddedTags, expected) {
+		t.Errorf("VersionUpAllServiceTags() = %v, want %v", mockRegister.AddedTags, expected)
+	}
+	want := []usecase.ListRemoteTagRefsQuery{{RemoteAddr: &origin}}
+	if !reflect.DeepEqual(stub.Queries, want) {
+		t.Errorf("queries = %v, want %v", stub.Queries, want)
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+)
+
+// RecordingRegister registers tags through Register and remembers them,
+// so that the tags created by a usecase can be pushed or rolled back afterwards.
+type RecordingRegister struct {
+	Register   RegisterServiceTags
+	Registered []*domain.ServiceTagWithSemVer
+}
+
+func (r *RecordingRegister) Execute(cmd RegisterServiceTagsCommand) error {
+	if err := r.Register.Execute(cmd); err != nil {
+		return err
+	}
+	r.Registered = append(r.Registered, *cmd.Tags...)
+	return nil
+}
+
+// RolledBackError is returned by PushRegisteredTags when the push failed
+// and the registered tags were deleted from the local repository.
+type RolledBackError struct {
+	Tags []*domain.ServiceTagWithSemVer
+	Err  error
+}
+
+func (e *RolledBackError) Error() string {
+	return fmt.Sprintf("push failed, deleted local tags %s: %s", joinTags(e.Tags), e.Err.Error())
+}
+
+func (e *RolledBackError) Unwrap() error {
+	return e.Err
+}
+
+// PushRegisteredTags pushes the tags that were just registered to remote.
+// When rollback is set the push is atomic, and if it fails the tags are deleted
+// through destroyer so that the local repository returns to its prior state.
+func PushRegisteredTags(
+	pusher CommitPusher,
+	destroyer DestroyServiceTags,
+	remote *domain.RemoteAddr,
+	tags []*domain.ServiceTagWithSemVer,
+	atomic bool,
+	rollback bool,
+) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	err := pusher.Execute(CommitPushCommand{
+		RemoteAddr: remote,
+		Tags:       &tags,
+		// without atomic some tags could reach the remote and be deleted locally
+		Atomic: atomic || rollback,
+	})
+	if err == nil || !rollback {
+		return err
+	}
+	if destroyErr := destroyer.Execute(DestroyServiceTagsCommand{Tags: &tags}); destroyErr != nil {
+		return fmt.Errorf("push failed: %w, and failed to delete local tags: %s", err, destroyErr.Error())
+	}
+	return &RolledBackError{Tags: tags, Err: err}
+}
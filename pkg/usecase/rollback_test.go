@@ -0,0 +1,65 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+type FailingPusher struct {
+	Commands []usecase.CommitPushCommand
+}
+
+func (f *FailingPusher) Execute(cmd usecase.CommitPushCommand) error {
+	f.Commands = append(f.Commands, cmd)
+	return errors.New("rejected")
+}
+
+func TestPushRegisteredTagsRollsBackOnFailure(t *testing.T) {
+	commitId := domain.HEAD
+	recorder := &usecase.RecordingRegister{Register: &MockRegister{}}
+	err := usecase.CreateServiceTags(recorder, &commitId, []domain.ServiceName{"service-a", "service-b"}, domain.NewSemVer(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pusher := &FailingPusher{}
+	destroyer := &MockDestroyer{}
+	origin := domain.Origin
+	err = usecase.PushRegisteredTags(pusher, destroyer, &origin, recorder.Registered, false, true)
+
+	var rolledBack *usecase.RolledBackError
+	if !errors.As(err, &rolledBack) {
+		t.Fatalf("PushRegisteredTags() error = %v, want RolledBackError", err)
+	}
+	if !pusher.Commands[0].Atomic {
+		t.Errorf("push with rollback must be atomic")
+	}
+	expected := []*domain.ServiceTagWithSemVer{
+		domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 0, 0)),
+		domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 0, 0)),
+	}
+	if destroyer.Destroyed == nil || !cmpArrayContent(*destroyer.Destroyed, expected) {
+		t.Errorf("destroyed = %v, want %v", destroyer.Destroyed, expected)
+	}
+}
+
+func TestPushRegisteredTagsKeepsTagsWithoutRollback(t *testing.T) {
+	tags := []*domain.ServiceTagWithSemVer{
+		domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 0, 0)),
+	}
+	pusher := &FailingPusher{}
+	destroyer := &MockDestroyer{}
+	origin := domain.Origin
+	err := usecase.PushRegisteredTags(pusher, destroyer, &origin, tags, false, false)
+	if err == nil {
+		t.Fatal("PushRegisteredTags() error = nil, want push error")
+	}
+	if pusher.Commands[0].Atomic {
+		t.Errorf("push without atomic and rollback must not be atomic")
+	}
+	if destroyer.Destroyed != nil {
+		t.Errorf("destroyed = %v, want nothing", *destroyer.Destroyed)
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"sort"
+)
+
+// LatestServiceVersions returns the highest version of every service, sorted by service name.
+// Every service with a tag is returned when services is empty.
+func LatestServiceVersions(services []domain.ServiceName, list ListTagRefs) ([]domain.ServiceVersion, error) {
+	infos, err := ServiceTagsList(services, list)
+	if err != nil {
+		return nil, err
+	}
+	latest := map[domain.ServiceName]*ServiceTagInfo{}
+	for _, info := range infos {
+		if current, ok := latest[info.Tag.Service]; !ok || info.Tag.GreaterThan(current.Tag) {
+			latest[info.Tag.Service] = info
+		}
+	}
+	versions := make([]domain.ServiceVersion, 0, len(latest))
+	for _, info := range latest {
+		versions = append(versions, domain.NewServiceVersion(info.Tag, *info.CommitId))
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Service < versions[j].Service
+	})
+	return versions, nil
+}
+
+// ServiceVersionsOf returns the versions of tags with their commits, in the order of tags.
+func ServiceVersionsOf(tags []*domain.ServiceTagWithSemVer, list ListTagRefs) ([]domain.ServiceVersion, error) {
+	refs, err := list.Execute(ListTagRefsQuery{})
+	if err != nil {
+		return nil, err
+	}
+	commits := map[domain.GitTag]domain.CommitId{}
+	for _, ref := range *refs {
+		commits[ref.Tag] = ref.CommitId
+	}
+	versions := make([]domain.ServiceVersion, 0, len(tags))
+	for _, tag := range tags {
+		commitId, ok := commits[tag.ToGitTag()]
+		if !ok {
+			return nil, fmt.Errorf("tag %s not found", tag.String())
+		}
+		versions = append(versions, domain.NewServiceVersion(tag, commitId))
+	}
+	return versions, nil
+}
+
+// StateServiceVersions returns the latest versions recorded in a state file, sorted by service name.
+// Every recorded service is returned when services is empty, services without a latest tag are skipped.
+func StateServiceVersions(state *domain.WritedState, services []domain.ServiceName) []domain.ServiceVersion {
+	versions := []domain.ServiceVersion{}
+	for _, s := range state.ServiceTagStates {
+		if s.Latest == nil || (len(services) > 0 && !containsService(services, *s.ServiceName)) {
+			continue
+		}
+		versions = append(versions, domain.NewServiceVersion(s.Latest.Tag, *s.Latest.CommitId))
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Service < versions[j].Service
+	})
+	return versions
+}
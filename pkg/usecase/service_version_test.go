@@ -0,0 +1,59 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestLatestServiceVersions(t *testing.T) {
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "web-v0.1.0", CommitId: "0000001"},
+		{Tag: "api-v1.10.0", CommitId: "0000003"},
+		{Tag: "api-v1.9.0", CommitId: "0000002"},
+		{Tag: "not-a-service-tag", CommitId: "0000003"},
+	}}
+	versions, err := usecase.LatestServiceVersions(nil, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.ServiceVersion{
+		{Service: "api", Version: "1.10.0", Tag: "api-v1.10.0", Commit: "0000003"},
+		{Service: "web", Version: "0.1.0", Tag: "web-v0.1.0", Commit: "0000001"},
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("LatestServiceVersions() = %v, want %v", versions, want)
+	}
+
+	versions, err = usecase.ServiceVersionsOf(*serviceTagsOf("api-v1.9.0"), list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Commit != "0000002" {
+		t.Errorf("ServiceVersionsOf() = %v, want api-v1.9.0 at 0000002", versions)
+	}
+	if _, err := usecase.ServiceVersionsOf(*serviceTagsOf("api-v2.0.0"), list); err == nil {
+		t.Error("ServiceVersionsOf() of a missing tag error = nil, want an error")
+	}
+}
+
+func TestStateServiceVersions(t *testing.T) {
+	state := domain.InitStateWriter("web", "api", "worker")
+	commitId := domain.CommitId("0000002")
+	state.Update("api", &domain.ServiceTagInfo{Tag: domain.NewServiceTagWithSemVer("api", domain.NewSemVer(1, 2, 0)), CommitId: &commitId}, nil)
+	state.Update("web", &domain.ServiceTagInfo{Tag: domain.NewServiceTagWithSemVer("web", domain.NewSemVer(0, 1, 0)), CommitId: &commitId}, nil)
+
+	versions := usecase.StateServiceVersions(state, nil)
+	want := []domain.ServiceVersion{
+		{Service: "api", Version: "1.2.0", Tag: "api-v1.2.0", Commit: "0000002"},
+		{Service: "web", Version: "0.1.0", Tag: "web-v0.1.0", Commit: "0000002"},
+	}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("StateServiceVersions() = %v, want %v", versions, want)
+	}
+	versions = usecase.StateServiceVersions(state, []domain.ServiceName{"web", "worker"})
+	if !reflect.DeepEqual(versions, want[1:]) {
+		t.Errorf("StateServiceVersions(web, worker) = %v, want %v", versions, want[1:])
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"msgtm/pkg/domain"
+	"sort"
+)
+
+// ServiceStatus compares the latest tag of a service in the repository with the state file.
+type ServiceStatus struct {
+	Service domain.ServiceName
+	// Latest is the latest service tag in the repository, nil when the service has no tag.
+	Latest *ServiceTagInfo
+	// Recorded is the latest tag of the state file, nil when it records none.
+	Recorded *ServiceTagInfo
+}
+
+// InSync reports whether the state file records the latest tag of the repository.
+func (s *ServiceStatus) InSync() bool {
+	if s.Latest == nil || s.Recorded == nil {
+		return s.Latest == nil && s.Recorded == nil
+	}
+	return s.Latest.Tag.Equal(s.Recorded.Tag) && *s.Latest.CommitId == *s.Recorded.CommitId
+}
+
+// ServiceStatuses returns the status of every service of the state file and the repository,
+// sorted by service name.
+func ServiceStatuses(state *domain.WritedState, list ListTagRefs) ([]*ServiceStatus, error) {
+	infos, err := ServiceTagsList(nil, list)
+	if err != nil {
+		return nil, err
+	}
+	statuses := map[domain.ServiceName]*ServiceStatus{}
+	statusOf := func(service domain.ServiceName) *ServiceStatus {
+		status, ok := statuses[service]
+		if !ok {
+			status = &ServiceStatus{Service: service}
+			statuses[service] = status
+		}
+		return status
+	}
+	for _, info := range infos {
+		status := statusOf(info.Tag.Service)
+		if status.Latest == nil || info.Tag.GreaterThan(status.Latest.Tag) {
+			status.Latest = info
+		}
+	}
+	for _, serviceState := range state.ServiceTagStates {
+		status := statusOf(*serviceState.ServiceName)
+		if serviceState.Latest != nil {
+			status.Recorded = &ServiceTagInfo{
+				Tag:      serviceState.Latest.Tag,
+				CommitId: serviceState.Latest.CommitId,
+			}
+		}
+	}
+
+	result := make([]*ServiceStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Service < result[j].Service
+	})
+	return result, nil
+}
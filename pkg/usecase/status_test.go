@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+func TestServiceStatuses(t *testing.T) {
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.0.0", CommitId: "0000001"},
+		{Tag: "service-a-v1.1.0", CommitId: "0000002"},
+		{Tag: "service-b-v0.1.0", CommitId: "0000001"},
+	}}
+	recordedA := domain.CommitId("0000002")
+	recordedC := domain.CommitId("0000003")
+	state := &domain.WritedState{ServiceTagStates: []*domain.ServiceTagState{
+		stateOf("service-a", domain.NewSemVer(1, 1, 0), &recordedA),
+		stateOf("service-c", domain.NewSemVer(2, 0, 0), &recordedC),
+		domain.InitServiceTagState(ptr(domain.ServiceName("service-b"))),
+	}}
+
+	statuses, err := usecase.ServiceStatuses(state, list)
+	if err != nil {
+		t.Fatalf("ServiceStatuses() error = %v, want nil", err)
+	}
+	want := map[domain.ServiceName]bool{
+		"service-a": true,
+		// tagged but not recorded
+		"service-b": false,
+		// recorded but not tagged
+		"service-c": false,
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("ServiceStatuses() = %d statuses, want %d", len(statuses), len(want))
+	}
+	for _, status := range statuses {
+		if status.InSync() != want[status.Service] {
+			t.Errorf("%s InSync() = %v, want %v", status.Service, status.InSync(), want[status.Service])
+		}
+	}
+	if statuses[0].Latest.Tag.Version != domain.NewSemVer(1, 1, 0) {
+		t.Errorf("service-a latest = %v, want v1.1.0", statuses[0].Latest.Tag)
+	}
+}
+
+func stateOf(service domain.ServiceName, version domain.SemVer, commitId *domain.CommitId) *domain.ServiceTagState {
+	state := domain.InitServiceTagState(&service)
+	state.UpdateLatest(&domain.ServiceTagInfo{
+		Tag:      domain.NewServiceTagWithSemVer(service, version),
+		CommitId: commitId,
+	})
+	return state
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
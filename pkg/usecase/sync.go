@@ -4,43 +4,37 @@ import (
 	"msgtm/pkg/domain"
 )
 
-func SyncAllServiceTagState(state *domain.WritedState, list ListTags, finder CommitFinder) (*domain.WritedState, error) {
-	tags, err := list.Execute(ListTagsQuery{
-		Filter: func(_ *domain.ServiceName) bool {
-			return true
-		}})
+func SyncAllServiceTagState(state *domain.WritedState, list ListTagRefs) (*domain.WritedState, error) {
+	refs, err := list.Execute(ListTagRefsQuery{})
 	if err != nil {
 		return nil, err
 	}
-	serviceTags := domain.FilterServiceTags(tags)
-	sorts := domain.SortsServiceTags(serviceTags)
-	for serviceName, tags := range sorts {
-		latest := tags[len(tags)-1]
-		gitTagLatest := latest.ToGitTag()
-		query := FindCommitQuery{
-			Tag: &gitTagLatest,
-		}
-		commitId, err := finder.Execute(query)
+	// keyed by the normalized tag, so that tags without "v" are found too
+	commitIds := map[string]domain.CommitId{}
+	serviceTags := []*domain.ServiceTagWithSemVer{}
+	for _, ref := range *refs {
+		serviceTag, err := ref.Tag.ToServiceTag()
 		if err != nil {
-			return nil, err
+			continue
 		}
+		commitIds[serviceTag.String()] = ref.CommitId
+		serviceTags = append(serviceTags, serviceTag)
+	}
+	sorts := domain.SortsServiceTags(&serviceTags)
+	for serviceName, tags := range sorts {
+		latest := tags[len(tags)-1]
+		commitId := commitIds[latest.String()]
 		info := domain.ServiceTagInfo{
 			Tag:      latest,
-			CommitId: commitId,
+			CommitId: &commitId,
 		}
 		var prev *domain.ServiceTagInfo = nil
 		if len(tags) > 1 {
 			tag := tags[len(tags)-2]
-			gitTag := tag.ToGitTag()
-			commitId, err := finder.Execute(FindCommitQuery{
-				Tag: &gitTag,
-			})
-			if err != nil {
-				return nil, err
-			}
+			commitId := commitIds[tag.String()]
 			prev = &domain.ServiceTagInfo{
 				Tag:      tag,
-				CommitId: commitId,
+				CommitId: &commitId,
 			}
 		}
 		state.Update(serviceName, &info, prev)
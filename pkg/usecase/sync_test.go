@@ -0,0 +1,49 @@
+package usecase_test
+
+import (
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"reflect"
+	"testing"
+)
+
+func TestSyncAllServiceTagState(t *testing.T) {
+	stub := &StubTagRefList{
+		refs: []domain.TagRef{
+			{Tag: "service-a-v1.2.3", CommitId: "commit3"},
+			{Tag: "service-a-v1.2.2", CommitId: "commit2"},
+			{Tag: "service-a-v1.0.0", CommitId: "commit1"},
+			// without "v"
+			{Tag: "service-b-1.0.0", CommitId: "commit1"},
+			{Tag: "release", CommitId: "commit3"},
+		},
+	}
+	state, err := usecase.SyncAllServiceTagState(domain.InitStateWriter("service-a", "service-b", "service-c"), stub)
+	if err != nil {
+		t.Fatalf("SyncAllServiceTagState() error = %v, want nil", err)
+	}
+	commit1, commit2, commit3 := domain.CommitId("commit1"), domain.CommitId("commit2"), domain.CommitId("commit3")
+	serviceA, serviceB, serviceC := domain.ServiceName("service-a"), domain.ServiceName("service-b"), domain.ServiceName("service-c")
+	want := &domain.WritedState{
+		ServiceTagStates: []*domain.ServiceTagState{
+			{
+				ServiceName: &serviceA,
+				Latest:      &domain.ServiceTagInfo{Tag: domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 2, 3)), CommitId: &commit3},
+				Prev:        &domain.ServiceTagInfo{Tag: domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 2, 2)), CommitId: &commit2},
+			},
+			{
+				ServiceName: &serviceB,
+				Latest:      &domain.ServiceTagInfo{Tag: domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 0, 0)), CommitId: &commit1},
+			},
+			{
+				ServiceName: &serviceC,
+			},
+		},
+	}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("SyncAllServiceTagState() = %v, want %v", state, want)
+	}
+	if len(stub.Queries) != 1 {
+		t.Errorf("SyncAllServiceTagState() queried %d times, want 1", len(stub.Queries))
+	}
+}
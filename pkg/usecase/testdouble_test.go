@@ -1,6 +1,7 @@
 package usecase_test
 
 import (
+	"fmt"
 	"msgtm/pkg/domain"
 	"msgtm/pkg/usecase"
 )
@@ -42,3 +43,59 @@ type StubTagList struct {
 func (s *StubTagList) Execute(cmd usecase.ListTagsQuery) (*[]domain.GitTag, error) {
 	return s.tags, nil
 }
+
+type StubTagRefList struct {
+	refs    []domain.TagRef
+	Queries []usecase.ListTagRefsQuery
+}
+
+func (s *StubTagRefList) Execute(query usecase.ListTagRefsQuery) (*[]domain.TagRef, error) {
+	s.Queries = append(s.Queries, query)
+	result := []domain.TagRef{}
+	for _, ref := range s.refs {
+		if len(query.Services) == 0 || ref.IsServiceTagOf(query.Services) {
+			result = append(result, ref)
+		}
+	}
+	return &result, nil
+}
+
+type StubRemoteTagRefList struct {
+	refs    map[domain.RemoteAddr][]domain.TagRef
+	Queries []usecase.ListRemoteTagRefsQuery
+}
+
+func (s *StubRemoteTagRefList) Execute(query usecase.ListRemoteTagRefsQuery) (*[]domain.TagRef, error) {
+	s.Queries = append(s.Queries, query)
+	refs := s.refs[*query.RemoteAddr]
+	return &refs, nil
+}
+
+type MockFetcher struct {
+	Commands []usecase.FetchTagsCommand
+}
+
+func (m *MockFetcher) Execute(cmd usecase.FetchTagsCommand) error {
+	m.Commands = append(m.Commands, cmd)
+	return nil
+}
+
+// StubRemote is a remote repository that pushed tags are added to,
+// rejecting the tags of Rejects.
+type StubRemote struct {
+	refs    map[domain.RemoteAddr][]domain.TagRef
+	commits map[domain.GitTag]domain.CommitId
+	Rejects map[domain.RemoteAddr]domain.GitTag
+}
+
+func (s *StubRemote) Execute(cmd usecase.CommitPushCommand) error {
+	var err error
+	for _, tag := range *cmd.Tags {
+		if s.Rejects[*cmd.RemoteAddr] == tag.ToGitTag() {
+			err = fmt.Errorf("failed to push some refs to %s", *cmd.RemoteAddr)
+			continue
+		}
+		s.refs[*cmd.RemoteAddr] = append(s.refs[*cmd.RemoteAddr], domain.TagRef{Tag: tag.ToGitTag(), CommitId: s.commits[tag.ToGitTag()]})
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"strings"
+)
+
+// TransactionalRegister registers either all of the tags of a command or none of them.
+// Every tag is checked before any of them is created, and when creating a tag fails
+// the tags created before it are deleted through Destroyer.
+type TransactionalRegister struct {
+	Register  RegisterServiceTags
+	Destroyer DestroyServiceTags
+	List      ListTagRefs
+}
+
+// InvalidTagsError is returned when some tags can not be created, nothing is tagged then.
+type InvalidTagsError struct {
+	Errors []error
+}
+
+func (e *InvalidTagsError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "no service was tagged: " + strings.Join(messages, ", ")
+}
+
+// RegistrationError reports which services were and weren't tagged after a failed registration.
+type RegistrationError struct {
+	// Failed is the tag that could not be created.
+	Failed *domain.ServiceTagWithSemVer
+	// RolledBack are the tags that were created and deleted again.
+	RolledBack []*domain.ServiceTagWithSemVer
+	// Tagged are the tags that remain because the rollback failed.
+	Tagged []*domain.ServiceTagWithSemVer
+	// Untagged are the tags that were never created.
+	Untagged []*domain.ServiceTagWithSemVer
+	Err      error
+	// RollbackErr is the error of deleting the created tags.
+	RollbackErr error
+}
+
+func (e *RegistrationError) Error() string {
+	message := fmt.Sprintf("failed to tag %s: %s", e.Failed.String(), e.Err.Error())
+	if len(e.RolledBack) > 0 {
+		message += fmt.Sprintf("; rolled back %s", joinTags(e.RolledBack))
+	}
+	if len(e.Tagged) > 0 {
+		message += fmt.Sprintf("; still tagged %s (rollback failed: %s)", joinTags(e.Tagged), e.RollbackErr.Error())
+	}
+	if len(e.Untagged) > 0 {
+		message += fmt.Sprintf("; not tagged %s", joinTags(e.Untagged))
+	}
+	return message
+}
+
+func (e *RegistrationError) Unwrap() error {
+	return e.Err
+}
+
+func (t *TransactionalRegister) Execute(cmd RegisterServiceTagsCommand) error {
+	if err := ValidateNewTags(*cmd.Tags, t.List); err != nil {
+		return err
+	}
+	created := []*domain.ServiceTagWithSemVer{}
+	for i, tag := range *cmd.Tags {
+		err := t.Register.Execute(RegisterServiceTagsCommand{
+			CommitId: cmd.CommitId,
+			Tags:     &[]*domain.ServiceTagWithSemVer{tag},
+		})
+		if err == nil {
+			created = append(created, tag)
+			continue
+		}
+		regErr := &RegistrationError{
+			Failed:   tag,
+			Untagged: append([]*domain.ServiceTagWithSemVer{}, (*cmd.Tags)[i:]...),
+			Err:      err,
+		}
+		if len(created) == 0 {
+			return regErr
+		}
+		if rollbackErr := t.Destroyer.Execute(DestroyServiceTagsCommand{Tags: &created}); rollbackErr != nil {
+			regErr.Tagged = created
+			regErr.RollbackErr = rollbackErr
+			return regErr
+		}
+		regErr.RolledBack = created
+		return regErr
+	}
+	return nil
+}
+
+// ValidateNewTags checks that every tag has a valid ref name, does not exist yet and is given once.
+func ValidateNewTags(tags []*domain.ServiceTagWithSemVer, list ListTagRefs) error {
+	services := []domain.ServiceName{}
+	for _, tag := range tags {
+		services = append(services, tag.Service)
+	}
+	refs, err := list.Execute(ListTagRefsQuery{Services: services})
+	if err != nil {
+		return err
+	}
+	existing := map[domain.GitTag]domain.CommitId{}
+	for _, ref := range *refs {
+		existing[ref.Tag] = ref.CommitId
+	}
+
+	errs := []error{}
+	seen := map[domain.GitTag]bool{}
+	for _, tag := range tags {
+		gitTag := tag.ToGitTag()
+		if err := gitTag.ValidateRefName(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if commitId, ok := existing[gitTag]; ok {
+			errs = append(errs, fmt.Errorf("tag %s already exists at %s", gitTag, commitId.String()))
+			continue
+		}
+		if seen[gitTag] {
+			errs = append(errs, fmt.Errorf("tag %s is given more than once", gitTag))
+		}
+		seen[gitTag] = true
+	}
+	if len(errs) > 0 {
+		return &InvalidTagsError{Errors: errs}
+	}
+	return nil
+}
+
+func joinTags(tags []*domain.ServiceTagWithSemVer) string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+// FailingRegister fails to create the tag of Fails and records the others.
+type FailingRegister struct {
+	Fails   domain.GitTag
+	Created []*domain.ServiceTagWithSemVer
+}
+
+func (f *FailingRegister) Execute(cmd usecase.RegisterServiceTagsCommand) error {
+	for _, tag := range *cmd.Tags {
+		if tag.ToGitTag() == f.Fails {
+			return errors.New("cannot lock ref")
+		}
+		f.Created = append(f.Created, tag)
+	}
+	return nil
+}
+
+func TestTransactionalRegisterRollsBackCreatedTags(t *testing.T) {
+	a := domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 0, 0))
+	b := domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 0, 0))
+	c := domain.NewServiceTagWithSemVer("service-c", domain.NewSemVer(1, 0, 0))
+	register := &FailingRegister{Fails: b.ToGitTag()}
+	destroyer := &MockDestroyer{}
+	sut := &usecase.TransactionalRegister{
+		Register:  register,
+		Destroyer: destroyer,
+		List:      &StubTagRefList{},
+	}
+	commitId := domain.HEAD
+	err := sut.Execute(usecase.RegisterServiceTagsCommand{
+		CommitId: &commitId,
+		Tags:     &[]*domain.ServiceTagWithSemVer{a, b, c},
+	})
+
+	var regErr *usecase.RegistrationError
+	if !errors.As(err, &regErr) {
+		t.Fatalf("Execute() error = %v, want RegistrationError", err)
+	}
+	if regErr.Failed != b {
+		t.Errorf("Failed = %v, want %v", regErr.Failed, b)
+	}
+	if !cmpArrayContent(regErr.RolledBack, []*domain.ServiceTagWithSemVer{a}) {
+		t.Errorf("RolledBack = %v, want %v", regErr.RolledBack, a)
+	}
+	if !cmpArrayContent(regErr.Untagged, []*domain.ServiceTagWithSemVer{b, c}) {
+		t.Errorf("Untagged = %v, want %v and %v", regErr.Untagged, b, c)
+	}
+	if destroyer.Destroyed == nil || !cmpArrayContent(*destroyer.Destroyed, []*domain.ServiceTagWithSemVer{a}) {
+		t.Errorf("Destroyed = %v, want %v", destroyer.Destroyed, a)
+	}
+}
+
+func TestTransactionalRegisterValidatesBeforeCreating(t *testing.T) {
+	a := domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 0, 0))
+	invalid := domain.NewServiceTagWithSemVer("service b", domain.NewSemVer(1, 0, 0))
+	register := &FailingRegister{}
+	sut := &usecase.TransactionalRegister{
+		Register:  register,
+		Destroyer: &MockDestroyer{},
+		List: &StubTagRefList{refs: []domain.TagRef{
+			{Tag: a.ToGitTag(), CommitId: "0123456"},
+		}},
+	}
+	commitId := domain.HEAD
+	err := sut.Execute(usecase.RegisterServiceTagsCommand{
+		CommitId: &commitId,
+		Tags:     &[]*domain.ServiceTagWithSemVer{a, invalid},
+	})
+
+	var invalidErr *usecase.InvalidTagsError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Execute() error = %v, want InvalidTagsError", err)
+	}
+	if len(invalidErr.Errors) != 2 {
+		t.Errorf("Errors = %v, want the existing and the invalid tag", invalidErr.Errors)
+	}
+	if len(register.Created) != 0 {
+		t.Errorf("Created = %v, want nothing", register.Created)
+	}
+}
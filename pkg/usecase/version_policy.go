@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"fmt"
+	"msgtm/pkg/domain"
+	"sort"
+	"strings"
+)
+
+// VersionPolicy relaxes the checks of CheckNewVersions.
+type VersionPolicy struct {
+	// AllowDowngrade allows versions lower than the latest tag of the service.
+	AllowDowngrade bool
+	// AllowSkip allows versions that are not the next patch, minor or major version.
+	AllowSkip bool
+}
+
+// VersionViolation explains why the new version of a service was refused.
+type VersionViolation struct {
+	Service domain.ServiceName
+	Reason  string
+}
+
+// VersionViolationsError is returned by CheckNewVersions when some services can not get the version.
+type VersionViolationsError struct {
+	Violations []VersionViolation
+}
+
+func (e *VersionViolationsError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, fmt.Sprintf("  %s: %s", violation.Service, violation.Reason))
+	}
+	return "refused versions:\n" + strings.Join(messages, "\n")
+}
+
+// CheckNewVersions compares the new tags with the latest tag of each service.
+// A new tag must not exist on another commit, must not be lower than the latest tag and must be
+// the next patch, minor or major version of it, unless the policy allows otherwise.
+// It returns the tags that already tag the commit, adding them again is a no-op.
+func CheckNewVersions(
+	tags []*domain.ServiceTagWithSemVer,
+	commitId *domain.CommitId,
+	policy VersionPolicy,
+	list ListTagRefs,
+	finder CommitFinder,
+) ([]*domain.ServiceTagWithSemVer, error) {
+	services := []domain.ServiceName{}
+	for _, tag := range tags {
+		services = append(services, tag.Service)
+	}
+	refs, err := list.Execute(ListTagRefsQuery{Services: services})
+	if err != nil {
+		return nil, err
+	}
+	// the commit finder resolves any revision, not only tags
+	revision := domain.GitTag(commitId.String())
+	target, err := finder.Execute(FindCommitQuery{Tag: &revision})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[domain.ServiceName][]*domain.ServiceTagWithSemVer{}
+	commits := map[string]domain.CommitId{}
+	for _, ref := range *refs {
+		serviceTag, err := ref.Tag.ToServiceTag()
+		if err != nil {
+			continue
+		}
+		existing[serviceTag.Service] = append(existing[serviceTag.Service], serviceTag)
+		commits[serviceTag.String()] = ref.CommitId
+	}
+
+	tagged := []*domain.ServiceTagWithSemVer{}
+	violations := []VersionViolation{}
+	for _, tag := range tags {
+		if commits[tag.String()] == *target {
+			tagged = append(tagged, tag)
+			continue
+		}
+		if reason := checkNewVersion(tag, existing[tag.Service], commits, policy); reason != "" {
+			violations = append(violations, VersionViolation{Service: tag.Service, Reason: reason})
+		}
+	}
+	if len(violations) > 0 {
+		return nil, &VersionViolationsError{Violations: violations}
+	}
+	return tagged, nil
+}
+
+func checkNewVersion(
+	tag *domain.ServiceTagWithSemVer,
+	existing []*domain.ServiceTagWithSemVer,
+	commits map[string]domain.CommitId,
+	policy VersionPolicy,
+) string {
+	if len(existing) == 0 {
+		return ""
+	}
+	if commitId, ok := commits[tag.String()]; ok {
+		return fmt.Sprintf("%s already exists at %s", tag.String(), commitId.String())
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].LessThan(existing[j])
+	})
+	latest := existing[len(existing)-1]
+	if tag.LessThan(latest) {
+		if policy.AllowDowngrade {
+			return ""
+		}
+		return fmt.Sprintf("%s is lower than the latest %s, use --allow-downgrade to add it anyway", tag.Version.String(), latest.String())
+	}
+	next := []domain.SemVer{latest.Version.PatchUp(), latest.Version.MinorUp(), latest.Version.MajorUp()}
+	for _, version := range next {
+		if tag.Version.Equal(version) {
+			return ""
+		}
+	}
+	if policy.AllowSkip {
+		return ""
+	}
+	expected := make([]string, 0, len(next))
+	for _, version := range next {
+		expected = append(expected, version.String())
+	}
+	return fmt.Sprintf("%s skips versions after the latest %s, expected one of %s, use --allow-skip to add it anyway",
+		tag.Version.String(), latest.String(), strings.Join(expected, ", "))
+}
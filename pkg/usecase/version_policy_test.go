@@ -0,0 +1,74 @@
+package usecase_test
+
+import (
+	"errors"
+	"msgtm/pkg/domain"
+	"msgtm/pkg/usecase"
+	"testing"
+)
+
+func TestCheckNewVersions(t *testing.T) {
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.2.0", CommitId: "0000001"},
+		{Tag: "service-a-v3.2.1", CommitId: "0000002"},
+	}}
+	finder := &StubCommitFinder{commitId: "0000003"}
+	head := domain.HEAD
+	tests := []struct {
+		name    string
+		version domain.SemVer
+		policy  usecase.VersionPolicy
+		refused bool
+	}{
+		{"next patch", domain.NewSemVer(3, 2, 2), usecase.VersionPolicy{}, false},
+		{"next minor", domain.NewSemVer(3, 3, 0), usecase.VersionPolicy{}, false},
+		{"next major", domain.NewSemVer(4, 0, 0), usecase.VersionPolicy{}, false},
+		{"downgrade", domain.NewSemVer(1, 0, 0), usecase.VersionPolicy{}, true},
+		{"allowed downgrade", domain.NewSemVer(1, 0, 0), usecase.VersionPolicy{AllowDowngrade: true}, false},
+		{"skip", domain.NewSemVer(3, 9, 0), usecase.VersionPolicy{}, true},
+		{"allowed skip", domain.NewSemVer(3, 9, 0), usecase.VersionPolicy{AllowSkip: true}, false},
+		{"duplicate on another commit", domain.NewSemVer(1, 2, 0), usecase.VersionPolicy{AllowDowngrade: true, AllowSkip: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := []*domain.ServiceTagWithSemVer{
+				domain.NewServiceTagWithSemVer("service-a", tt.version),
+				// a service without tags accepts any version
+				domain.NewServiceTagWithSemVer("service-b", tt.version),
+			}
+			_, err := usecase.CheckNewVersions(tags, &head, tt.policy, list, finder)
+			if !tt.refused {
+				if err != nil {
+					t.Errorf("CheckNewVersions() error = %v, want nil", err)
+				}
+				return
+			}
+			var violations *usecase.VersionViolationsError
+			if !errors.As(err, &violations) {
+				t.Fatalf("CheckNewVersions() error = %v, want VersionViolationsError", err)
+			}
+			if len(violations.Violations) != 1 || violations.Violations[0].Service != "service-a" {
+				t.Errorf("Violations = %v, want one of service-a", violations.Violations)
+			}
+		})
+	}
+}
+
+func TestCheckNewVersionsAcceptsTagsOfTheCommit(t *testing.T) {
+	list := &StubTagRefList{refs: []domain.TagRef{
+		{Tag: "service-a-v1.2.0", CommitId: "0000001"},
+	}}
+	finder := &StubCommitFinder{commitId: "0000001"}
+	head := domain.HEAD
+	tags := []*domain.ServiceTagWithSemVer{
+		domain.NewServiceTagWithSemVer("service-a", domain.NewSemVer(1, 2, 0)),
+		domain.NewServiceTagWithSemVer("service-b", domain.NewSemVer(1, 2, 0)),
+	}
+	tagged, err := usecase.CheckNewVersions(tags, &head, usecase.VersionPolicy{}, list, finder)
+	if err != nil {
+		t.Fatalf("CheckNewVersions() error = %v, want nil", err)
+	}
+	if len(tagged) != 1 || tagged[0].String() != "service-a-v1.2.0" {
+		t.Errorf("CheckNewVersions() = %v, want service-a-v1.2.0", tagged)
+	}
+}